@@ -6,10 +6,10 @@ require (
 	github.com/google/uuid v1.3.0
 	github.com/jade-kinx/go-study/golang-basic/examples v0.0.0-20230417025316-86c13daf57ea
 	github.com/stretchr/testify v1.8.2
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
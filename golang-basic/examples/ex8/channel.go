@@ -1,9 +1,12 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"time"
+
+	"gostudy/pkg/queue"
 )
 
 // tick for busy waiting
@@ -27,10 +30,14 @@ func NewChannel[T any](cap int) *Channel[T] {
 
 // 채널 종료
 func (c *Channel[T]) Close() {
+	c.lock()
+	defer c.unlock()
 	c.closed = true
 }
 
 func (c *Channel[T]) Closed() bool {
+	c.lock()
+	defer c.unlock()
 	return c.closed
 }
 
@@ -96,7 +103,7 @@ func (c *Channel[T]) IsEmpty() bool {
 func (c *Channel[T]) Push(o T) error {
 	for {
 		// channel closed?
-		if c.closed {
+		if c.Closed() {
 			return fmt.Errorf("push to closed channel")
 		}
 
@@ -115,7 +122,7 @@ func (c *Channel[T]) Push(o T) error {
 func (c *Channel[T]) Pop() (o T, err error) {
 	for {
 		// channel closed?
-		if c.closed {
+		if c.Closed() {
 			return o, fmt.Errorf("channel closed")
 		}
 
@@ -156,3 +163,108 @@ func (c *Channel[T]) TryPopForConcurrentNotSafe() (o T, ok bool) {
 	}
 	return o, false
 }
+
+/////////////////////////////////////////////////////////////////////////
+// queue.BlockingQueue 구현
+// 기존 Push, Pop과 달리 닫힌 채널에 남은 데이터도 꺼낼 수 있다(Go 채널과 같음).
+/////////////////////////////////////////////////////////////////////////
+
+// 컴파일 타임에 queue.BlockingQueue 구현 여부 검사
+var _ queue.BlockingQueue[int] = (*Channel[int])(nil)
+
+// 채널에 데이터를 푸시(대기하지 않음)
+// 닫혔으면 queue.ErrClosed, 가득찼으면 queue.ErrFull
+func (c *Channel[T]) Offer(o T) error {
+	c.lock()
+	defer c.unlock()
+
+	if c.closed {
+		return queue.ErrClosed
+	}
+	if c.Count() >= c.cap {
+		return queue.ErrFull
+	}
+	c.q = append(c.q, o)
+	return nil
+}
+
+// 채널에서 데이터를 팝(대기하지 않음)
+// 비어있으면 queue.ErrEmpty, 닫히고 비어있으면 queue.ErrClosed
+func (c *Channel[T]) Poll() (o T, err error) {
+	c.lock()
+	defer c.unlock()
+
+	if c.Count() > 0 {
+		o = c.q[0]
+		c.q = c.q[1:]
+		return o, nil
+	}
+	if c.closed {
+		return o, queue.ErrClosed
+	}
+	return o, queue.ErrEmpty
+}
+
+// 채널에 데이터를 푸시(자리가 날 때까지 대기)
+// ctx가 종료되면 ctx.Err()
+func (c *Channel[T]) Put(ctx context.Context, o T) error {
+	for {
+		err := c.Offer(o)
+		if err != queue.ErrFull {
+			return err
+		}
+
+		// wait for channel ready (busy-waiting)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(tick):
+		}
+	}
+}
+
+// 채널에서 데이터를 팝(데이터 있을때 까지 대기)
+// ctx가 종료되면 ctx.Err()
+func (c *Channel[T]) Take(ctx context.Context) (o T, err error) {
+	for {
+		o, err = c.Poll()
+		if err != queue.ErrEmpty {
+			return o, err
+		}
+
+		// wait for data (busy-waiting)
+		select {
+		case <-ctx.Done():
+			return o, ctx.Err()
+		case <-time.After(tick):
+		}
+	}
+}
+
+// 채널의 현재 버퍼 카운트(concurrent-safe)
+func (c *Channel[T]) Len() int {
+	c.lock()
+	defer c.unlock()
+	return len(c.q)
+}
+
+// 채널에서 데이터를 입력 순서대로 꺼내면서(소비) 반복한다.
+// 채널이 닫히고 남은 데이터를 모두 꺼내거나, ctx가 종료되면 반복을 멈춘다.
+//
+//	ch.All(ctx)(func(o T) bool { ... })  // go 1.20
+//	for o := range ch.All(ctx) { ... }   // go 1.23+
+func (c *Channel[T]) All(ctx context.Context) queue.Seq[T] {
+	return queue.All[T](ctx, c)
+}
+
+// 채널의 데이터를 꺼내지 않고 입력 순서대로 반복한다.
+// 반복을 시작하는 시점의 버퍼를 복사하여 순회한다.
+func (c *Channel[T]) Seq() queue.Seq[T] {
+	return func(yield func(T) bool) {
+		c.lock()
+		snapshot := append([]T(nil), c.q...)
+		c.unlock()
+
+		queue.Values(snapshot)(yield)
+	}
+}
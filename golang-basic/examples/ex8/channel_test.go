@@ -1,12 +1,18 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"runtime"
 	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
+
+	"gostudy/pkg/queue"
+	"gostudy/pkg/queue/queuetest"
+
+	"github.com/stretchr/testify/assert"
 )
 
 // 동기화 문제가 발생하면 패닉이 발생
@@ -18,10 +24,10 @@ func TestChannelShouldBeConcurrentSafe(t *testing.T) {
 	runtime.GOMAXPROCS(workers)
 
 	// timeout trigger
-	done := false
+	var done atomic.Bool // concurrent safe
 	go func(d time.Duration) {
 		<-time.After(d) // wait for timeout
-		done = true
+		done.Store(true)
 	}(time.Second * 10)
 
 	// pushed, poped count
@@ -31,7 +37,7 @@ func TestChannelShouldBeConcurrentSafe(t *testing.T) {
 	wg.Add(workers)
 	for i := 0; i < workers; i++ {
 		go func(x int) {
-			for !done {
+			for !done.Load() {
 				ch.Push(x)                   // ch <- x (push to channel)
 				atomic.AddUint64(&pushed, 1) // concurrent safe
 			}
@@ -43,7 +49,7 @@ func TestChannelShouldBeConcurrentSafe(t *testing.T) {
 	wg.Add(workers)
 	for i := 0; i < workers; i++ {
 		go func(x int) {
-			for !done {
+			for !done.Load() {
 				ch.Pop()                    // <- ch (pop from channel)
 				atomic.AddUint64(&poped, 1) // concurrent safe
 			}
@@ -93,6 +99,36 @@ func TestChannelUsageForSelectLike(t *testing.T) {
 	}
 }
 
+// Channel은 입력 순(FIFO)으로 꺼내는 queue.BlockingQueue 이다
+func TestChannelConformance(t *testing.T) {
+	queuetest.Run(t, queuetest.Config[int]{
+		New:   func(cap int) queue.Queue[int] { return NewChannel[int](cap) },
+		Item:  func(i int) int { return i },
+		Index: func(item int) int { return item },
+	})
+}
+
+// All은 입력 순서대로 꺼내면서 반복하고, Seq는 꺼내지 않고 반복한다
+func TestChannelIteration(t *testing.T) {
+	assert := assert.New(t)
+
+	ch := NewChannel[int](10)
+	for i := 0; i < 3; i++ {
+		ch.Push(i)
+	}
+	assert.Equal([]int{0, 1, 2}, queue.Collect(ch.Seq()))
+	assert.Equal(3, ch.Len())
+
+	ch.Close()
+	var popped []int
+	ch.All(context.Background())(func(o int) bool {
+		popped = append(popped, o)
+		return true
+	})
+	assert.Equal([]int{0, 1, 2}, popped)
+	assert.Equal(0, ch.Len())
+}
+
 func TestMutexLockTwice(t *testing.T) {
 	// timeout
 	go func() {
@@ -0,0 +1,93 @@
+// 시계(clock) 추상화
+//
+// time.Now, time.Sleep, time.NewTimer를 직접 호출하는 대신 Clock을 주입받으면,
+// 테스트에서는 Fake 시계를 직접 진행(Advance)시켜 실제로 기다리지 않고 결정적으로 테스트할 수 있다.
+package clock
+
+import "time"
+
+// 시계
+type Clock interface {
+	// 현재 시간
+	Now() time.Time
+	// t 이후로 지난 시간
+	Since(t time.Time) time.Duration
+	// d 후에 현재 시간을 전달하는 채널
+	After(d time.Duration) <-chan time.Time
+	// d 만큼 대기
+	Sleep(d time.Duration)
+	// d 후에 만료되는 타이머
+	NewTimer(d time.Duration) Timer
+}
+
+// 타이머(time.Timer와 같은 동작)
+type Timer interface {
+	// 만료되면 현재 시간을 전달하는 채널
+	C() <-chan time.Time
+	// 타이머를 멈춘다. 이미 만료되었거나 멈춘 타이머면 false
+	Stop() bool
+	// d 후에 만료되도록 재설정한다. 재설정 전에 타이머가 동작중이었으면 true
+	Reset(d time.Duration) bool
+	// deadline에 만료되도록 재설정한다. 재설정 전에 타이머가 동작중이었으면 true
+	// Now로 남은 시간을 계산하고 Reset 하는 사이에 (가짜) 시간이 흘러도 정확한 시간에 만료된다.
+	ResetAt(deadline time.Time) bool
+}
+
+// 실제 시계
+var Real Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) Since(t time.Time) time.Duration {
+	return time.Since(t)
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (realClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+type realTimer struct {
+	*time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
+
+func (t realTimer) ResetAt(deadline time.Time) bool {
+	return t.Timer.Reset(time.Until(deadline))
+}
+
+// 타이머를 d 후로 재설정한다. 만료되었지만 읽지 않은 값은 버린다.
+func ResetTimer(timer Timer, d time.Duration) {
+	StopTimer(timer)
+	timer.Reset(d)
+}
+
+// 타이머를 deadline으로 재설정한다. 만료되었지만 읽지 않은 값은 버린다.
+func ResetTimerAt(timer Timer, deadline time.Time) {
+	StopTimer(timer)
+	timer.ResetAt(deadline)
+}
+
+// 타이머를 멈춘다. 만료되었지만 읽지 않은 값은 버린다.
+func StopTimer(timer Timer) {
+	if !timer.Stop() {
+		select {
+		case <-timer.C():
+		default:
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// 테스트용 가짜 시계
+// 시간은 Advance, Set을 호출할 때만 흐르며, 그 때 만료 시간이 지난 타이머(After, Sleep 포함)가 만료된다.
+// 모든 메소드는 concurrent-safe 하다.
+type Fake struct {
+	l       sync.Mutex
+	waiters *sync.Cond   // 타이머 추가 알림(BlockUntil)
+	now     time.Time    // 현재 시간
+	timers  []*fakeTimer // 동작중인 타이머
+}
+
+// 가짜 타이머
+type fakeTimer struct {
+	f        *Fake
+	c        chan time.Time
+	deadline time.Time
+	active   bool
+}
+
+// now에서 시작하는 가짜 시계 생성
+func NewFake(now time.Time) *Fake {
+	f := &Fake{now: now}
+	f.waiters = sync.NewCond(&f.l)
+	return f
+}
+
+// 현재 시간
+func (f *Fake) Now() time.Time {
+	f.l.Lock()
+	defer f.l.Unlock()
+	return f.now
+}
+
+// t 이후로 지난 시간
+func (f *Fake) Since(t time.Time) time.Duration {
+	return f.Now().Sub(t)
+}
+
+// d 만큼 시간이 흐른 후에 현재 시간을 전달하는 채널
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	return f.NewTimer(d).C()
+}
+
+// d 만큼 시간이 흐를 때까지 대기(다른 고루틴에서 Advance 해야 한다)
+func (f *Fake) Sleep(d time.Duration) {
+	<-f.After(d)
+}
+
+// d 만큼 시간이 흐르면 만료되는 타이머
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	t := &fakeTimer{f: f, c: make(chan time.Time, 1)}
+	t.Reset(d)
+	return t
+}
+
+// 시간을 d 만큼 진행시키고, 만료 시간이 지난 타이머를 만료 시간 순으로 만료시킨다.
+func (f *Fake) Advance(d time.Duration) {
+	f.Set(f.Now().Add(d))
+}
+
+// 현재 시간을 t로 설정하고, 만료 시간이 지난 타이머를 만료 시간 순으로 만료시킨다.
+// 시간을 되돌릴 수는 없다(t가 현재 시간보다 이전이면 무시).
+func (f *Fake) Set(t time.Time) {
+	f.l.Lock()
+	defer f.l.Unlock()
+
+	if t.Before(f.now) {
+		return
+	}
+	f.now = t
+
+	sort.Slice(f.timers, func(i, j int) bool { return f.timers[i].deadline.Before(f.timers[j].deadline) })
+	expired := 0
+	for _, timer := range f.timers {
+		if timer.deadline.After(t) {
+			break
+		}
+		timer.fire(t)
+		expired++
+	}
+	f.timers = f.timers[expired:]
+}
+
+// 동작중인 타이머(After, Sleep 포함) 수
+func (f *Fake) Waiters() int {
+	f.l.Lock()
+	defer f.l.Unlock()
+	return len(f.timers)
+}
+
+// 동작중인 타이머가 n개 이상이 될 때까지 대기한다.
+// 다른 고루틴이 Sleep, 타이머 대기를 시작한 후에 Advance 하기 위해 사용한다.
+func (f *Fake) BlockUntil(n int) {
+	f.l.Lock()
+	defer f.l.Unlock()
+	for len(f.timers) < n {
+		f.waiters.Wait()
+	}
+}
+
+// 타이머를 만료시킨다. 시계의 락을 잡은 상태에서 호출
+func (t *fakeTimer) fire(now time.Time) {
+	t.active = false
+	// 읽지 않은 이전 값이 있으면 버리고 새 시각을 보낸다(재설정한 타이머가 지난 시각을 전달하지 않게)
+	select {
+	case <-t.c:
+	default:
+	}
+	t.c <- now
+}
+
+// 동작중인 타이머 목록에서 제거한다. 시계의 락을 잡은 상태에서 호출
+func (t *fakeTimer) remove() bool {
+	if !t.active {
+		return false
+	}
+	t.active = false
+	for i, timer := range t.f.timers {
+		if timer == t {
+			t.f.timers = append(t.f.timers[:i], t.f.timers[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.f.l.Lock()
+	defer t.f.l.Unlock()
+	return t.remove()
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.f.l.Lock()
+	defer t.f.l.Unlock()
+	return t.resetAt(t.f.now.Add(d))
+}
+
+func (t *fakeTimer) ResetAt(deadline time.Time) bool {
+	t.f.l.Lock()
+	defer t.f.l.Unlock()
+	return t.resetAt(deadline)
+}
+
+// deadline에 만료되도록 재설정한다. 시계의 락을 잡은 상태에서 호출
+func (t *fakeTimer) resetAt(deadline time.Time) bool {
+	active := t.remove()
+	t.deadline = deadline
+	if !deadline.After(t.f.now) {
+		t.fire(t.f.now)
+		return active
+	}
+	t.active = true
+	t.f.timers = append(t.f.timers, t)
+	t.f.waiters.Broadcast()
+	return active
+}
@@ -0,0 +1,120 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var epoch = time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)
+
+// 만료 전인지 검사
+func fired(c <-chan time.Time) bool {
+	select {
+	case <-c:
+		return true
+	default:
+		return false
+	}
+}
+
+// 시간은 Advance 할 때만 흐르고, 만료 시간이 지난 타이머만 만료된다
+func TestFakeShouldFireTimersOnAdvance(t *testing.T) {
+	assert := assert.New(t)
+
+	f := NewFake(epoch)
+	assert.Equal(epoch, f.Now())
+
+	short := f.NewTimer(time.Second)
+	long := f.NewTimer(time.Minute)
+	assert.Equal(2, f.Waiters())
+
+	f.Advance(time.Millisecond * 999)
+	assert.False(fired(short.C()))
+
+	f.Advance(time.Millisecond)
+	assert.Equal(epoch.Add(time.Second), <-short.C())
+	assert.False(fired(long.C()))
+	assert.Equal(1, f.Waiters())
+	assert.Equal(time.Second, f.Since(epoch))
+
+	// 시간을 되돌릴 수 없다
+	f.Set(epoch)
+	assert.Equal(epoch.Add(time.Second), f.Now())
+
+	f.Set(epoch.Add(time.Hour))
+	assert.True(fired(long.C()))
+	assert.Equal(0, f.Waiters())
+}
+
+// Stop, Reset은 time.Timer와 같이 동작한다
+func TestFakeTimerStopAndReset(t *testing.T) {
+	assert := assert.New(t)
+
+	f := NewFake(epoch)
+	timer := f.NewTimer(time.Second)
+	assert.True(timer.Stop())
+	assert.False(timer.Stop())
+	f.Advance(time.Second)
+	assert.False(fired(timer.C()))
+
+	assert.False(timer.Reset(time.Second))
+	assert.True(timer.Reset(time.Second * 2))
+	f.Advance(time.Second)
+	assert.False(fired(timer.C()))
+	f.Advance(time.Second)
+	assert.True(fired(timer.C()))
+
+	// 0 이하면 바로 만료
+	ResetTimer(timer, 0)
+	assert.True(fired(timer.C()))
+
+	// 절대 시간으로 재설정
+	deadline := f.Now().Add(time.Minute)
+	ResetTimerAt(timer, deadline)
+	f.Advance(time.Second * 30)
+	assert.False(fired(timer.C()))
+	f.Set(deadline)
+	assert.Equal(deadline, <-timer.C())
+	ResetTimerAt(timer, epoch)
+	assert.True(fired(timer.C()))
+}
+
+// 읽지 않은 값이 있는 타이머가 다시 만료되면 새 시각을 전달한다
+func TestFakeTimerShouldDeliverLatestTime(t *testing.T) {
+	assert := assert.New(t)
+
+	f := NewFake(epoch)
+	timer := f.NewTimer(time.Second)
+	f.Advance(time.Second)
+
+	// 읽지 않고 재설정
+	assert.False(timer.Reset(time.Second))
+	f.Advance(time.Second)
+	assert.Equal(epoch.Add(time.Second*2), <-timer.C())
+	assert.False(fired(timer.C()))
+}
+
+// Sleep은 다른 고루틴이 시간을 진행시킬 때까지 대기한다
+func TestFakeSleepShouldWaitForAdvance(t *testing.T) {
+	assert := assert.New(t)
+
+	f := NewFake(epoch)
+	woke := make(chan time.Time)
+	go func() {
+		f.Sleep(time.Minute)
+		woke <- f.Now()
+	}()
+
+	f.BlockUntil(1)
+	f.Advance(time.Second * 59)
+	select {
+	case <-woke:
+		t.Fatal("sleep should wait until the clock is advanced")
+	case <-time.After(time.Millisecond * 10):
+	}
+
+	f.Advance(time.Second)
+	assert.Equal(epoch.Add(time.Minute), <-woke)
+}
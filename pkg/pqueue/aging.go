@@ -0,0 +1,79 @@
+package pqueue
+
+import (
+	"container/heap"
+	"math"
+	"time"
+)
+
+// 대기 시간에 따라 우선순위를 보정하는 함수(aging)
+// 원래 우선순위와 대기 시간을 받아 유효 우선순위를 반환한다.
+// 우선순위가 낮은 아이템이 계속 밀려나는 기아(starvation) 상태를 막기 위함
+type AgingFunc[P any] func(priority P, waited time.Duration) P
+
+// aging을 적용할 수 있는 부호있는 숫자 타입
+type signed interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 | ~float32 | ~float64
+}
+
+// 지수 aging 보정값의 최대 지수(overflow 방지)
+const maxAgingExp = 52
+
+// 선형 aging: every 마다 우선순위를 step 만큼 앞당긴다.
+// 작은 값이 우선인 채널 기준이며, 큰 값이 우선인 채널은 음수 step을 사용한다.
+// 예: LinearAging(1, time.Second) // 1초 대기할 때마다 우선순위 -1
+func LinearAging[P signed](step P, every time.Duration) AgingFunc[P] {
+	return func(priority P, waited time.Duration) P {
+		return priority - P(float64(step)*float64(waited)/float64(every))
+	}
+}
+
+// 지수 aging: 보정값이 every 마다 두배씩 커진다. step * (2^(waited/every) - 1)
+// 작은 값이 우선인 채널 기준이며, 큰 값이 우선인 채널은 음수 step을 사용한다.
+func ExponentialAging[P signed](step P, every time.Duration) AgingFunc[P] {
+	return func(priority P, waited time.Duration) P {
+		exp := math.Min(float64(waited)/float64(every), maxAgingExp)
+		return priority - P(float64(step)*(math.Exp2(exp)-1))
+	}
+}
+
+// aging 정책을 설정한다(nil: 사용안함)
+//
+// 유효 우선순위는 pop 할 때 모든 아이템에 대해 다시 계산하고 힙을 재구성하므로 O(N)이 든다.
+// resolution 주기 이내의 pop에서는 재계산하지 않으므로, 큐가 큰 경우 적당한 주기를 지정한다.
+func (c *PriorityChannel[T, P]) SetAging(aging AgingFunc[P], resolution time.Duration) {
+	c.wLock()
+	defer c.wUnlock()
+
+	c.aging = aging
+	c.resolution = resolution
+	c.agedAt = time.Time{}
+
+	// 유효 우선순위 초기화
+	if aging == nil {
+		for _, item := range c.q.items {
+			item.effective = item.priority
+		}
+		heap.Init(&c.q)
+		return
+	}
+	c.age()
+}
+
+// 대기중인 아이템의 유효 우선순위를 다시 계산한다. 락을 잡은 상태에서 호출
+func (c *PriorityChannel[T, P]) age() {
+	if c.aging == nil {
+		return
+	}
+
+	now := c.clock.Now()
+	if now.Sub(c.agedAt) < c.resolution {
+		return
+	}
+	c.agedAt = now
+
+	for _, item := range c.q.items {
+		item.effective = c.aging(item.priority, now.Sub(item.enqueuedAt))
+	}
+	heap.Init(&c.q)
+}
@@ -0,0 +1,101 @@
+package pqueue
+
+import (
+	"testing"
+	"time"
+
+	"gostudy/pkg/clock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// aging 함수는 대기 시간에 따라 우선순위를 앞당긴다
+func TestAgingFuncShouldImprovePriorityByWaitTime(t *testing.T) {
+	assert := assert.New(t)
+
+	linear := LinearAging(2, time.Second)
+	assert.Equal(10, linear(10, 0))
+	assert.Equal(8, linear(10, time.Second))
+	assert.Equal(0, linear(10, time.Second*5))
+
+	exponential := ExponentialAging(1.0, time.Second)
+	assert.Equal(10.0, exponential(10, 0))
+	assert.Equal(9.0, exponential(10, time.Second))
+	assert.Equal(7.0, exponential(10, time.Second*2))
+	assert.Equal(3.0, exponential(10, time.Second*3))
+
+	// 큰 값이 우선인 채널은 음수 step
+	maxFirst := LinearAging(-1, time.Second)
+	assert.Equal(13, maxFirst(10, time.Second*3))
+}
+
+// 급한 아이템이 계속 들어와도 aging이 적용되면 대기중인 아이템이 결국 처리된다
+func TestPriorityChannelAgingShouldPreventStarvation(t *testing.T) {
+	assert := assert.New(t)
+
+	// 급한 아이템을 하나 넣고 하나 꺼내는 것을 반복하며, 낮은 우선순위 아이템이 처리되는지 확인
+	starve := func(pc *PriorityChannel[string, int], rounds int) (Item[string, int], bool) {
+		fake := clock.NewFake(epoch)
+		pc.SetClock(fake)
+		assert.NoError(push(pc, "low", 50))
+		for i := 0; i < rounds; i++ {
+			assert.NoError(push(pc, "urgent", 0))
+			item, err := pc.PopItem()
+			assert.NoError(err)
+			if item.Data == "low" {
+				return item, true
+			}
+			fake.Advance(time.Millisecond)
+		}
+		return Item[string, int]{}, false
+	}
+
+	// aging 없음: 낮은 우선순위 아이템은 처리되지 않는다
+	_, ok := starve(NewChannel[string](10), 100)
+	assert.False(ok)
+
+	// 1ms 대기마다 우선순위 -1: 50ms 후에는 급한 아이템과 같아지고, 먼저 입력되었으므로 먼저 처리된다
+	pc := NewChannel[string](10)
+	pc.SetAging(LinearAging(1, time.Millisecond), 0)
+	item, ok := starve(pc, 1000)
+	assert.True(ok)
+	assert.Equal(50, item.Priority)
+	assert.Equal(0, item.Effective)
+	assert.Equal(time.Millisecond*50, item.Waited)
+}
+
+// aging을 해제하면 원래 우선순위로 되돌아간다
+func TestPriorityChannelSetAgingNilShouldRestorePriority(t *testing.T) {
+	assert := assert.New(t)
+	fake := clock.NewFake(epoch)
+	pc := NewChannel[string](10)
+	pc.SetClock(fake)
+	pc.SetAging(LinearAging(100, time.Millisecond), 0)
+
+	assert.NoError(push(pc, "old", 10))
+	fake.Advance(time.Millisecond * 5)
+	assert.NoError(push(pc, "new", 5))
+
+	pc.SetAging(nil, 0)
+	item, err := pc.PopItem()
+	assert.NoError(err)
+	assert.Equal("new", item.Data)
+	assert.Equal(item.Priority, item.Effective)
+}
+
+// PopItem은 아이템별 대기 시간을 반환한다
+func TestPriorityChannelPopItemShouldReturnWaitTime(t *testing.T) {
+	assert := assert.New(t)
+	fake := clock.NewFake(epoch)
+	pc := NewChannel[int](10)
+	pc.SetClock(fake)
+
+	assert.NoError(push(pc, 1, 1))
+	fake.Advance(time.Millisecond * 10)
+
+	item, err := pc.PopItem()
+	assert.NoError(err)
+	assert.Equal(1, item.Data)
+	assert.Equal(epoch, item.EnqueuedAt)
+	assert.Equal(time.Millisecond*10, item.Waited)
+}
@@ -0,0 +1,184 @@
+package pqueue
+
+import (
+	"container/heap"
+	"context"
+	"sort"
+	"time"
+)
+
+// 여러 아이템을 한번에 추가한다(대기하지 않음)
+// 모두 추가되거나 하나도 추가되지 않으며(all-or-nothing), handles[i]는 items[i]의 핸들이다.
+// 추가된 아이템은 items의 순서대로 입력 순서(seq)가 정해진다. 닫힌 채널이면 ErrClosed
+// WAL을 사용하면 모든 변경을 먼저 한번에 기록하므로, 기록에 실패해도 채널은 그대로다.
+//
+// cap을 넘으면 오버플로 정책을 배치 전체에 적용한다.
+//   - Block, Reject: ErrFull
+//   - DropNewest: 배치 전체를 버리고 ErrDropped
+//   - EvictLowestPriority: 넘치는 수 만큼 가장 낮은 우선순위의 아이템을 버리고 배치를 추가한다.
+//     버려질 아이템 중에 배치의 가장 낮은 아이템보다 앞선 것이 있으면(배치의 아이템끼리 밀어내게 되면)
+//     배치 전체를 버리고 ErrDropped
+func (c *PriorityChannel[T, P]) PushBatch(items []Item[T, P]) ([]*Handle[T, P], error) {
+	c.wLock()
+	defer c.wUnlock()
+
+	if c.closed {
+		c.rejectBatch(items, ErrClosed)
+		return nil, ErrClosed
+	}
+	if len(items) == 0 {
+		return []*Handle[T, P]{}, nil
+	}
+
+	over := c.q.Len() + len(items) - c.cap
+	if over > 0 && c.overflow != EvictLowestPriority {
+		if c.overflow == DropNewest {
+			c.dropBatch(items)
+			return nil, ErrDropped
+		}
+		c.rejectBatch(items, ErrFull)
+		return nil, ErrFull
+	}
+
+	if over > 0 {
+		c.age()
+	}
+	pending := make([]*heapItem[T, P], 0, len(items))
+	for _, item := range items {
+		pending = append(pending, c.newItem(item.Data, item.Priority))
+	}
+	var victims []*heapItem[T, P]
+	if over > 0 {
+		var ok bool
+		if victims, ok = c.victims(pending, over); !ok {
+			c.dropBatch(items)
+			return nil, ErrDropped
+		}
+	}
+
+	if err := c.logBatch(victims, pending); err != nil {
+		return nil, err
+	}
+	now := c.clock.Now()
+	for _, v := range victims {
+		heap.Remove(&c.q, v.index)
+		c.observeEvict(v)
+		c.evict(v.item(now))
+	}
+	handles := make([]*Handle[T, P], 0, len(items))
+	for _, item := range pending {
+		handles = append(handles, c.insert(item))
+	}
+	return handles, nil
+}
+
+// 배치를 거절한다. 락을 잡은 상태에서 호출
+func (c *PriorityChannel[T, P]) rejectBatch(items []Item[T, P], err error) {
+	for _, item := range items {
+		c.observeReject(item.Priority, err)
+	}
+}
+
+// 배치 전체를 버리고 콜백에 전달한다. 락을 잡은 상태에서 호출
+func (c *PriorityChannel[T, P]) dropBatch(items []Item[T, P]) {
+	now := c.clock.Now()
+	for _, item := range items {
+		c.observeReject(item.Priority, ErrDropped)
+		c.evict(Item[T, P]{Data: item.Data, Priority: item.Priority, Effective: item.Priority, EnqueuedAt: now})
+	}
+}
+
+// 배치가 들어갈 자리를 만들기 위해 버릴 n개의 아이템(가장 낮은 우선순위 순)을 고른다. 락을 잡은 상태에서 호출
+// 버릴 아이템이 모두 배치의 가장 낮은 아이템보다 낮아야 하며, 그렇지 않으면 false
+// 새 아이템은 seq가 가장 크므로, 우선순위가 같으면 새 아이템이 더 낮다(단일 push와 같음).
+func (c *PriorityChannel[T, P]) victims(batch []*heapItem[T, P], n int) ([]*heapItem[T, P], bool) {
+	if n > c.q.Len() {
+		return nil, false
+	}
+
+	worst := batch[0]
+	for _, item := range batch[1:] {
+		if c.q.before(worst, item) {
+			worst = item
+		}
+	}
+
+	// 낮은 우선순위 순으로 정렬: O(NlogN)
+	sorted := append([]*heapItem[T, P](nil), c.q.items...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return c.q.before(sorted[j], sorted[i])
+	})
+	victims := sorted[:n]
+	if !c.q.before(worst, victims[n-1]) {
+		return nil, false
+	}
+	return victims, true
+}
+
+// 최대 n개의 아이템을 한번에 꺼낸다(적어도 하나 있을때 까지 대기)
+// 하나의 락 안에서 연속으로 꺼내므로 단일 Pop을 n번 호출한 것과 같은 순서를 보장한다.
+// ctx가 종료되면 ctx.Err(), 채널이 닫히고 남은 아이템이 없으면 ErrClosed
+func (c *PriorityChannel[T, P]) PopN(ctx context.Context, n int) ([]Item[T, P], error) {
+	return c.PopUntil(ctx, n, 0)
+}
+
+// 최대 n개의 아이템을 모아서 꺼낸다(micro-batching)
+// 첫 아이템이 있을때 까지 대기한 뒤, n개가 모이거나 첫 아이템 이후 maxWait이 지나면 반환한다.
+// maxWait이 0 이하면 PopN과 같다.
+//
+// ctx 종료, 채널 종료시 이미 꺼낸 아이템이 있으면 에러 없이 반환한다.
+// 아이템이 없으면 ctx.Err(), ErrClosed를 반환한다.
+func (c *PriorityChannel[T, P]) PopUntil(ctx context.Context, n int, maxWait time.Duration) ([]Item[T, P], error) {
+	if n <= 0 {
+		return nil, nil
+	}
+
+	items := make([]Item[T, P], 0, n)
+	var timeout <-chan time.Time
+
+	for {
+		c.wLock()
+		var err error
+		for len(items) < n {
+			var x *heapItem[T, P]
+			if x, err = c.pop(); err != nil {
+				break
+			}
+			items = append(items, x.item(c.clock.Now()))
+		}
+
+		// n개 모두 꺼냈거나, 기다리지 않는 경우
+		if len(items) == n || (len(items) > 0 && maxWait <= 0) {
+			c.wUnlock()
+			return items, nil
+		}
+		if err != ErrEmpty {
+			c.wUnlock()
+			if len(items) > 0 && err == ErrClosed {
+				return items, nil
+			}
+			return items, err
+		}
+		changed := c.changed()
+		c.wUnlock()
+
+		// 첫 아이템을 꺼낸 시점부터 maxWait
+		if len(items) > 0 && timeout == nil {
+			timer := c.clock.NewTimer(maxWait)
+			defer timer.Stop()
+			timeout = timer.C()
+		}
+
+		// wait for data
+		select {
+		case <-changed:
+		case <-timeout:
+			return items, nil
+		case <-ctx.Done():
+			if len(items) > 0 {
+				return items, nil
+			}
+			return nil, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package pqueue
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"gostudy/pkg/rng"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// PushBatch는 cap을 넘으면 하나도 추가하지 않는다(all-or-nothing)
+func TestPriorityChannelPushBatchShouldBeAllOrNothing(t *testing.T) {
+	assert := assert.New(t)
+	pc := NewChannel[string](3)
+	assert.NoError(push(pc, "x", 0))
+
+	_, err := pc.PushBatch([]Item[string, int]{{Data: "a"}, {Data: "b"}, {Data: "c"}})
+	assert.ErrorIs(err, ErrFull)
+	assert.Equal(1, pc.Count())
+
+	handles, err := pc.PushBatch([]Item[string, int]{{Data: "a", Priority: 1}, {Data: "b", Priority: 0}})
+	assert.NoError(err)
+	assert.Len(handles, 2)
+	assert.Equal(3, pc.Count())
+
+	// 같은 우선순위는 배치 내의 순서를 유지한다
+	assert.Equal([]string{"x", "b", "a"}, drainAll(pc))
+
+	pc.Close()
+	_, err = pc.PushBatch([]Item[string, int]{{Data: "late"}})
+	assert.ErrorIs(err, ErrClosed)
+}
+
+// PopN은 단일 Pop을 반복한 것과 같은 순서로 최대 n개를 반환한다
+func TestPriorityChannelPopNShouldKeepPopOrder(t *testing.T) {
+	assert := assert.New(t)
+	count := 1000
+	pc, expected := NewChannel[int](count), NewChannel[int](count)
+
+	batch := []Item[int, int]{}
+	for i := 0; i < count; i++ {
+		priority := rng.NextInRange(math.MinInt8, math.MaxInt8)
+		batch = append(batch, Item[int, int]{Data: i, Priority: priority})
+		assert.NoError(push(expected, i, priority))
+	}
+	_, err := pc.PushBatch(batch)
+	assert.NoError(err)
+
+	ctx := context.Background()
+	got := []int{}
+	for pc.Count() > 0 {
+		items, err := pc.PopN(ctx, 64)
+		assert.NoError(err)
+		assert.LessOrEqual(len(items), 64)
+		for _, item := range items {
+			got = append(got, item.Data)
+		}
+	}
+	assert.Equal(drainAll(expected), got)
+}
+
+// PopN은 아이템이 하나라도 있을 때까지 대기하고, 있는 만큼만 반환한다
+func TestPriorityChannelPopNShouldBlockUntilAtLeastOneItem(t *testing.T) {
+	assert := assert.New(t)
+	pc := NewChannel[int](10)
+
+	go func() {
+		time.Sleep(time.Millisecond * 10)
+		pc.PushBatch([]Item[int, int]{{Data: 1}, {Data: 2}})
+	}()
+
+	begin := time.Now()
+	items, err := pc.PopN(context.Background(), 5)
+	assert.NoError(err)
+	assert.GreaterOrEqual(time.Since(begin), time.Millisecond*10)
+	assert.NotEmpty(items)
+	assert.LessOrEqual(len(items), 2)
+
+	// ctx 종료
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	pc.PopN(ctx, 5) // 남은 아이템
+	_, err = pc.PopN(ctx, 5)
+	assert.ErrorIs(err, context.DeadlineExceeded)
+
+	// 닫힌 채널
+	pc.Close()
+	_, err = pc.PopN(context.Background(), 5)
+	assert.ErrorIs(err, ErrClosed)
+}
+
+// PopUntil은 n개가 모이거나 maxWait이 지날 때까지 아이템을 모은다
+func TestPriorityChannelPopUntilShouldCollectMicroBatch(t *testing.T) {
+	assert := assert.New(t)
+	pc := NewChannel[int](100)
+	ctx := context.Background()
+
+	// 천천히 들어오는 아이템
+	go func() {
+		for i := 0; i < 10; i++ {
+			pc.Push(i, 0)
+			time.Sleep(time.Millisecond * 5)
+		}
+	}()
+
+	// n개가 모이면 maxWait 전에 반환
+	begin := time.Now()
+	items, err := pc.PopUntil(ctx, 3, time.Second)
+	assert.NoError(err)
+	assert.Len(items, 3)
+	assert.Less(time.Since(begin), time.Second)
+
+	// maxWait이 지나면 모인 만큼 반환
+	items, err = pc.PopUntil(ctx, 100, time.Millisecond*12)
+	assert.NoError(err)
+	assert.NotEmpty(items)
+	assert.Less(len(items), 7)
+
+	// 닫히면 남은 아이템을 반환
+	time.Sleep(time.Millisecond * 50)
+	pc.Close()
+	items, err = pc.PopUntil(ctx, 100, time.Hour)
+	assert.NoError(err)
+	assert.NotEmpty(items)
+	assert.Equal(9, items[len(items)-1].Data)
+}
+
+// WAL 기록에 실패하면 PushBatch는 하나도 추가하지 않고, 다시 열어도 복구되지 않는다
+func TestPriorityChannelPushBatchShouldBeAllOrNothingOnWALFailure(t *testing.T) {
+	for name, policy := range map[string]OverflowPolicy{"reject": Reject, "drop": DropNewest} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			dir := t.TempDir()
+			opts := WALOptions{Codec: failingCodec{fail: func(rec walRecord[walJob, int]) bool {
+				return rec.Data.Name == "bad"
+			}}}
+
+			pc := openWALChannel(t, dir, opts)
+			pc.SetOverflow(policy, nil)
+			_, err := pc.Push(walJob{ID: 1, Name: "x"}, 0)
+			assert.NoError(err)
+
+			handles, err := pc.PushBatch([]Item[walJob, int]{
+				{Data: walJob{ID: 2, Name: "a"}, Priority: 1},
+				{Data: walJob{ID: 3, Name: "bad"}, Priority: 2},
+			})
+			assert.Error(err)
+			assert.Nil(handles)
+			assert.Equal(1, pc.Count())
+			assert.NoError(pc.CloseWAL())
+
+			pc = openWALChannel(t, dir, opts)
+			assert.Equal([]walJob{{ID: 1, Name: "x"}}, drainAll(pc))
+			assert.NoError(pc.CloseWAL())
+		})
+	}
+}
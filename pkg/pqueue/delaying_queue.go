@@ -0,0 +1,243 @@
+package pqueue
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"gostudy/pkg/clock"
+	"gostudy/pkg/queue"
+)
+
+// 종료된 큐(errors.Is로 queue.ErrClosed와 비교할 수 있다)
+var ErrShutDown = fmt.Errorf("queue is shutting down: %w", queue.ErrClosed)
+
+// 지연 큐 인터페이스(client-go의 DelayingInterface)
+// DelayingQueue(힙), TimingWheel(타이밍 휠)이 구현한다.
+type DelayingInterface[T any] interface {
+	queue.BlockingQueue[T]
+	// 아이템을 바로 추가한다.
+	Add(item T) error
+	// d 만큼 지난 후에 아이템을 추가한다.
+	AddAfter(item T, d time.Duration) error
+	// readyAt 시각에 아이템을 추가한다.
+	AddAt(item T, readyAt time.Time) error
+	// 준비된 아이템을 하나 꺼낸다(대기)
+	Get(ctx context.Context) (T, error)
+	// readyAt을 기다리는 아이템 수
+	Waiting() int
+	// 큐를 종료한다.
+	ShutDown()
+	// 큐가 종료되었나?
+	ShuttingDown() bool
+}
+
+// 지연 큐(delaying queue)
+//
+// client-go의 delaying_queue 처럼 waitForPriorityQueue에 readyAt 순으로 아이템을 보관하고,
+// 하나의 타이머 고루틴이 가장 빠른 readyAt에 맞춰 깨어나 준비된 아이템을 ready 큐로 옮긴다.
+// (busy-waiting 없음) 모든 메소드는 concurrent-safe 하다.
+type DelayingQueue[T any] struct {
+	l       sync.Mutex
+	clock   clock.Clock
+	waiting waitForPriorityQueue // readyAt 대기중인 아이템(min-heap)
+	ready   []T                  // 준비된 아이템(FIFO)
+	notify  chan struct{}        // ready 큐 상태 변경 알림
+	wake    chan struct{}        // 타이머 고루틴 깨우기(가장 빠른 readyAt 변경)
+	stop    chan struct{}        // 타이머 고루틴 종료
+	stopped chan struct{}        // 타이머 고루틴 종료됨
+	closed  bool                 // 큐 종료
+}
+
+// 지연 큐 생성(타이머 고루틴 시작)
+// 사용 후 반드시 ShutDown을 호출하여 타이머 고루틴을 종료한다.
+func NewDelayingQueue[T any]() *DelayingQueue[T] {
+	return NewDelayingQueueWithClock[T](clock.Real)
+}
+
+// 주어진 시계를 사용하는 지연 큐 생성(테스트에서 clock.Fake 사용)
+func NewDelayingQueueWithClock[T any](c clock.Clock) *DelayingQueue[T] {
+	q := &DelayingQueue[T]{
+		clock:   c,
+		wake:    make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	heap.Init(&q.waiting)
+	go q.waitingLoop()
+	return q
+}
+
+// 아이템을 바로 추가한다.
+func (q *DelayingQueue[T]) Add(item T) error {
+	return q.AddAt(item, time.Time{})
+}
+
+// d 만큼 지난 후에 아이템을 추가한다.
+func (q *DelayingQueue[T]) AddAfter(item T, d time.Duration) error {
+	if d <= 0 {
+		return q.Add(item)
+	}
+	return q.AddAt(item, q.clock.Now().Add(d))
+}
+
+// readyAt 시각에 아이템을 추가한다. 이미 지난 시각이면 바로 추가한다.
+func (q *DelayingQueue[T]) AddAt(item T, readyAt time.Time) error {
+	q.l.Lock()
+	defer q.l.Unlock()
+
+	if q.closed {
+		return ErrShutDown
+	}
+
+	// 이미 준비된 아이템
+	if !readyAt.After(q.clock.Now()) {
+		q.ready = append(q.ready, item)
+		q.broadcast()
+		return nil
+	}
+
+	entry := &waitFor{data: item, readyAt: readyAt}
+	heap.Push(&q.waiting, entry)
+
+	// 가장 빠른 아이템이 바뀌었으면 타이머 고루틴을 깨운다
+	if entry.index == 0 {
+		select {
+		case q.wake <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// 준비된 아이템을 하나 꺼낸다(준비된 아이템이 있을때 까지 대기)
+// ctx가 종료되면 ctx.Err(), 큐가 종료되고 준비된 아이템이 없으면 ErrShutDown
+func (q *DelayingQueue[T]) Get(ctx context.Context) (item T, err error) {
+	for {
+		q.l.Lock()
+		item, err = q.poll()
+		if err != queue.ErrEmpty {
+			q.l.Unlock()
+			return item, err
+		}
+		changed := q.changed()
+		q.l.Unlock()
+
+		// wait for ready item
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return item, ctx.Err()
+		}
+	}
+}
+
+// 준비된 아이템을 하나 꺼낸다. 락을 잡은 상태에서 호출
+// 준비된 아이템이 없으면 queue.ErrEmpty, 큐가 종료되었으면 ErrShutDown
+func (q *DelayingQueue[T]) poll() (item T, err error) {
+	if len(q.ready) > 0 {
+		item = q.ready[0]
+		var zero T
+		q.ready[0] = zero // avoid memory leak
+		q.ready = q.ready[1:]
+		return item, nil
+	}
+	if q.closed {
+		return item, ErrShutDown
+	}
+	return item, queue.ErrEmpty
+}
+
+// 준비된 아이템 수
+func (q *DelayingQueue[T]) Len() int {
+	q.l.Lock()
+	defer q.l.Unlock()
+	return len(q.ready)
+}
+
+// readyAt을 기다리는 아이템 수
+func (q *DelayingQueue[T]) Waiting() int {
+	q.l.Lock()
+	defer q.l.Unlock()
+	return q.waiting.Len()
+}
+
+// 큐를 종료한다. 타이머 고루틴을 종료하고 대기중인(readyAt 이전) 아이템은 버린다.
+// 이미 준비된 아이템은 Get으로 꺼낼 수 있다. 여러번 호출해도 안전하다.
+func (q *DelayingQueue[T]) ShutDown() {
+	q.l.Lock()
+	if q.closed {
+		q.l.Unlock()
+		<-q.stopped
+		return
+	}
+	q.closed = true
+	q.waiting = waitForPriorityQueue{}
+	q.broadcast()
+	q.l.Unlock()
+
+	close(q.stop)
+	<-q.stopped
+}
+
+// 큐가 종료되었나?
+func (q *DelayingQueue[T]) ShuttingDown() bool {
+	q.l.Lock()
+	defer q.l.Unlock()
+	return q.closed
+}
+
+// 타이머 고루틴
+// 가장 빠른 readyAt에 깨어나 준비된 아이템을 ready 큐로 옮긴다.
+// 대기중인 아이템이 없으면 새 아이템이 추가될 때까지 잠든다.
+func (q *DelayingQueue[T]) waitingLoop() {
+	defer close(q.stopped)
+
+	timer := q.clock.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		var next <-chan time.Time
+
+		q.l.Lock()
+		now := q.clock.Now()
+		for q.waiting.Len() > 0 {
+			entry := q.waiting.Peek().(*waitFor)
+			if entry.readyAt.After(now) {
+				// 다음 readyAt 까지 대기
+				clock.ResetTimerAt(timer, entry.readyAt)
+				next = timer.C()
+				break
+			}
+			heap.Pop(&q.waiting)
+			q.ready = append(q.ready, entry.data.(T))
+			q.broadcast()
+		}
+		q.l.Unlock()
+
+		select {
+		case <-q.stop:
+			return
+		case <-next:
+		case <-q.wake:
+		}
+	}
+}
+
+// ready 큐 상태 변경 알림 채널을 반환한다. 락을 잡은 상태에서 호출
+func (q *DelayingQueue[T]) changed() <-chan struct{} {
+	if q.notify == nil {
+		q.notify = make(chan struct{})
+	}
+	return q.notify
+}
+
+// ready 큐 상태 변경을 기다리는 고루틴을 모두 깨운다. 락을 잡은 상태에서 호출
+func (q *DelayingQueue[T]) broadcast() {
+	if q.notify != nil {
+		close(q.notify)
+		q.notify = nil
+	}
+}
@@ -0,0 +1,171 @@
+package pqueue
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"gostudy/pkg/clock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 지연 큐는 readyAt 순으로 아이템을 반환한다
+func TestDelayingQueueShouldGetItemsInReadyOrder(t *testing.T) {
+	assert := assert.New(t)
+	q := NewDelayingQueue[string]()
+	defer q.ShutDown()
+
+	begin := time.Now()
+	assert.NoError(q.AddAfter("c", time.Millisecond*30))
+	assert.NoError(q.AddAfter("a", time.Millisecond*10))
+	assert.NoError(q.AddAfter("b", time.Millisecond*20))
+	assert.Equal(3, q.Waiting())
+
+	ctx := context.Background()
+	for i, expected := range []string{"a", "b", "c"} {
+		item, err := q.Get(ctx)
+		assert.NoError(err)
+		assert.Equal(expected, item)
+		assert.GreaterOrEqual(time.Since(begin), time.Millisecond*time.Duration(10*(i+1)))
+	}
+	assert.Zero(q.Waiting())
+}
+
+// 가짜 시계를 사용하면 시간이 흐를 때만 준비된다
+func TestDelayingQueueWithFakeClock(t *testing.T) {
+	assert := assert.New(t)
+
+	fake := clock.NewFake(epoch)
+	q := NewDelayingQueueWithClock[string](fake)
+	defer q.ShutDown()
+
+	assert.NoError(q.AddAfter("hour", time.Hour))
+	assert.NoError(q.AddAt("minute", epoch.Add(time.Minute)))
+
+	fake.Advance(time.Minute - time.Nanosecond)
+	assert.Empty(drainReady[string](q, 0))
+	fake.Advance(time.Nanosecond)
+	assert.Equal([]string{"minute"}, drainReady[string](q, 1))
+
+	fake.Set(epoch.Add(time.Hour))
+	assert.Equal([]string{"hour"}, drainReady[string](q, 1))
+	assert.Zero(q.Waiting())
+}
+
+// 이미 지난 시각이나 0 이하의 지연은 바로 추가된다
+func TestDelayingQueueShouldAddImmediatelyWhenAlreadyReady(t *testing.T) {
+	assert := assert.New(t)
+	q := NewDelayingQueue[int]()
+	defer q.ShutDown()
+
+	assert.NoError(q.Add(1))
+	assert.NoError(q.AddAfter(2, 0))
+	assert.NoError(q.AddAt(3, time.Now().Add(-time.Hour)))
+	assert.Equal(3, q.Len())
+
+	for i := 1; i <= 3; i++ {
+		item, err := q.Get(context.Background())
+		assert.NoError(err)
+		assert.Equal(i, item)
+	}
+}
+
+// 나중에 추가된 아이템의 readyAt이 더 빠르면 타이머가 다시 설정되어야 한다
+func TestDelayingQueueShouldWakeUpForEarlierItem(t *testing.T) {
+	assert := assert.New(t)
+	q := NewDelayingQueue[string]()
+	defer q.ShutDown()
+
+	assert.NoError(q.AddAfter("late", time.Hour))
+	assert.NoError(q.AddAfter("early", time.Millisecond*10))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	item, err := q.Get(ctx)
+	assert.NoError(err)
+	assert.Equal("early", item)
+}
+
+// Get은 ctx가 종료되면 ctx.Err()를 반환한다
+func TestDelayingQueueGetShouldReturnOnContextDone(t *testing.T) {
+	assert := assert.New(t)
+	q := NewDelayingQueue[int]()
+	defer q.ShutDown()
+	assert.NoError(q.AddAfter(1, time.Hour))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	_, err := q.Get(ctx)
+	assert.ErrorIs(err, context.DeadlineExceeded)
+}
+
+// ShutDown 후에는 추가할 수 없고, 준비된 아이템을 모두 꺼내면 ErrShutDown
+func TestDelayingQueueShutDownShouldStopTimerGoroutine(t *testing.T) {
+	assert := assert.New(t)
+	before := runtime.NumGoroutine()
+
+	q := NewDelayingQueue[int]()
+	assert.NoError(q.Add(1))
+	assert.NoError(q.AddAfter(2, time.Hour))
+
+	// Get 대기중인 고루틴도 깨어나야 한다
+	q2 := NewDelayingQueue[int]()
+	errs := make(chan error)
+	go func() {
+		_, err := q2.Get(context.Background())
+		errs <- err
+	}()
+
+	q.ShutDown()
+	q.ShutDown()
+	q2.ShutDown()
+	assert.ErrorIs(<-errs, ErrShutDown)
+
+	assert.True(q.ShuttingDown())
+	assert.ErrorIs(q.Add(3), ErrShutDown)
+	item, err := q.Get(context.Background())
+	assert.NoError(err)
+	assert.Equal(1, item)
+	_, err = q.Get(context.Background())
+	assert.ErrorIs(err, ErrShutDown)
+
+	assertNoGoroutineLeak(t, before)
+}
+
+// 여러 고루틴에서 동시에 추가하고 꺼내도 안전하다
+// go test -race 로 실행하여 data race가 없는지 확인
+func TestDelayingQueueShouldBeConcurrentSafe(t *testing.T) {
+	assert := assert.New(t)
+	q := NewDelayingQueue[int]()
+	defer q.ShutDown()
+
+	workers, count := runtime.NumCPU(), 1000
+	wg := sync.WaitGroup{}
+
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < count; i++ {
+				assert.NoError(q.AddAfter(w*count+i, time.Duration(i%10)*time.Millisecond))
+			}
+		}(w)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	seen := make([]bool, workers*count)
+	for i := 0; i < workers*count; i++ {
+		item, err := q.Get(ctx)
+		if !assert.NoError(err) {
+			break
+		}
+		assert.False(seen[item])
+		seen[item] = true
+	}
+	wg.Wait()
+}
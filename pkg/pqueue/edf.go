@@ -0,0 +1,121 @@
+package pqueue
+
+import (
+	"container/heap"
+	"sort"
+	"time"
+)
+
+// 마감 시간(deadline)이 빠른 아이템을 먼저 꺼내는 채널(earliest deadline first) 생성
+//
+// 우선순위는 아이템의 마감 시간이며, 이미 마감 시간이 지난 아이템은 꺼내지 않고
+// onExpire 콜백에 전달한다(nil: 버림). 따라서 Pop으로 꺼낸 아이템은 항상 마감 전이다.
+//
+//	er := NewDeadlineChannel[Patient](100, func(item Item[Patient, time.Time]) {
+//		fmt.Println("patient dead!!!", item.Data)
+//	})
+//	er.Push(patient, patient.Deadline())
+func NewDeadlineChannel[T any](cap int, onExpire func(Item[T, time.Time])) *PriorityChannel[T, time.Time] {
+	c := NewChannelFunc[T](cap, Earlier)
+	// 채널의 시계(SetClock) 기준으로 만료를 판단한다
+	c.SetExpiry(func(deadline time.Time) bool {
+		return ExpiredAt(deadline, c.clock.Now())
+	}, onExpire)
+	return c
+}
+
+// 마감 시간 비교 함수(빠른 시간이 우선)
+func Earlier(a, b time.Time) bool {
+	return a.Before(b)
+}
+
+// now 시점에 마감 시간이 지났나?
+// 실제 시계(time.Now) 대신 채널의 시계나 시뮬레이션 시각을 now로 전달한다.
+func ExpiredAt(deadline, now time.Time) bool {
+	return !now.Before(deadline)
+}
+
+// 아이템의 만료 여부 함수와 만료된 아이템을 받을 콜백을 지정한다(expired nil: 사용안함)
+// 아이템을 꺼낼 때 맨 앞의 아이템부터 만료 여부를 검사하므로, 만료 순서가 우선순위 순서와
+// 같아야 한다(EDF). 콜백은 채널 락을 푼 뒤에 호출되므로 채널 메소드를 호출해도 된다.
+// aging(SetAging)을 함께 사용하면 유효 우선순위 순서가 만료 순서와 달라지므로, 꺼낼 때마다
+// 모든 아이템의 만료 여부를 검사한다: O(N)
+func (c *PriorityChannel[T, P]) SetExpiry(expired func(priority P) bool, onExpire func(Item[T, P])) {
+	c.wLock()
+	defer c.wUnlock()
+	c.expired = expired
+	c.onExpire = onExpire
+}
+
+// 만료된 아이템을 모두 콜백에 전달하고, 전달한 아이템 수를 반환한다.
+// 꺼낼 때 자동으로 정리되므로, 꺼내지 않는 동안 자리를 비우려는 경우에만 호출한다.
+func (c *PriorityChannel[T, P]) Expire() (int, error) {
+	c.wLock()
+	defer c.wUnlock()
+
+	n := c.q.Len()
+	err := c.expire()
+	return n - c.q.Len(), err
+}
+
+// 맨 앞의 만료된 아이템을 모두 꺼내 콜백에 전달한다. 락을 잡은 상태에서 호출
+func (c *PriorityChannel[T, P]) expire() error {
+	if c.expired == nil {
+		return nil
+	}
+
+	if c.aging != nil {
+		return c.expireAll()
+	}
+
+	expired := false
+	for c.q.Len() > 0 && c.expired(c.q.Peek().priority) {
+		if err := c.log(walRemove, c.q.Peek()); err != nil {
+			return err
+		}
+		item := heap.Pop(&c.q).(*heapItem[T, P])
+		c.checkpoint()
+		c.observeExpire(item)
+		c.later(c.onExpire, item.item(c.clock.Now()))
+		expired = true
+	}
+
+	// 자리가 났으므로 대기중인 push를 깨운다
+	if expired {
+		c.broadcast()
+	}
+	return nil
+}
+
+// 만료된 아이템을 모두 찾아서 콜백에 전달한다(aging 사용시). 락을 잡은 상태에서 호출
+// 힙은 유효 우선순위 순이므로, 만료된 아이템이 만료되지 않은 아이템 뒤에 있을 수 있다.
+func (c *PriorityChannel[T, P]) expireAll() error {
+	var expired []*heapItem[T, P]
+	for _, item := range c.q.items {
+		if c.expired(item.priority) {
+			expired = append(expired, item)
+		}
+	}
+	if len(expired) == 0 {
+		return nil
+	}
+
+	// 콜백에는 먼저 만료된(원래 우선순위가 앞선) 순서로 전달한다
+	sort.Slice(expired, func(i, j int) bool {
+		return c.q.less(expired[i].priority, expired[j].priority)
+	})
+	for _, item := range expired {
+		if err := c.log(walRemove, item); err != nil {
+			c.broadcast()
+			return err
+		}
+		heap.Remove(&c.q, item.index)
+		c.checkpoint()
+		c.observeExpire(item)
+		c.later(c.onExpire, item.item(c.clock.Now()))
+	}
+
+	// 자리가 났으므로 대기중인 push를 깨운다
+	c.broadcast()
+	return nil
+}
@@ -0,0 +1,158 @@
+package pqueue
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"gostudy/pkg/clock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 마감 시간이 빠른 순으로 꺼내고, 마감 시간이 지난 아이템은 콜백에 전달한다
+func TestDeadlineChannelShouldPopEarliestDeadlineAndExpireLate(t *testing.T) {
+	assert := assert.New(t)
+
+	var l sync.Mutex
+	var expired []string
+	pc := NewDeadlineChannel[string](10, func(item Item[string, time.Time]) {
+		l.Lock()
+		defer l.Unlock()
+		expired = append(expired, item.Data)
+	})
+	fake := clock.NewFake(epoch)
+	pc.SetClock(fake)
+
+	now := fake.Now()
+	assert.NoError(push(pc, "late", now.Add(time.Hour)))
+	assert.NoError(push(pc, "soon", now.Add(time.Minute)))
+	assert.NoError(push(pc, "dead-1", now.Add(-time.Second)))
+	assert.NoError(push(pc, "dying", now.Add(time.Millisecond*20)))
+	assert.NoError(push(pc, "dead-2", now.Add(-time.Minute)))
+
+	fake.Advance(time.Millisecond * 30)
+
+	item, err := pc.PopItem()
+	assert.NoError(err)
+	assert.Equal("soon", item.Data)
+	assert.True(item.Priority.After(fake.Now()))
+
+	// 먼저 만료된 순서로 콜백에 전달된다
+	l.Lock()
+	assert.Equal([]string{"dead-2", "dead-1", "dying"}, expired)
+	l.Unlock()
+
+	assert.Equal([]string{"late"}, drainAll(pc))
+}
+
+// aging을 함께 사용하면 유효 우선순위가 낮은 아이템도 만료된다
+func TestDeadlineChannelShouldExpireAgedOutItems(t *testing.T) {
+	assert := assert.New(t)
+
+	var expired []string
+	pc := NewDeadlineChannel[string](10, func(item Item[string, time.Time]) {
+		expired = append(expired, item.Data)
+	})
+	fake := clock.NewFake(epoch)
+	pc.SetClock(fake)
+	// 기다린 시간의 2배 만큼 마감 시간을 앞당긴다
+	pc.SetAging(func(deadline time.Time, waited time.Duration) time.Time {
+		return deadline.Add(-waited * 2)
+	}, 0)
+
+	assert.NoError(push(pc, "old", epoch.Add(time.Second*20)))
+	assert.NoError(push(pc, "older", epoch.Add(time.Second*19)))
+	fake.Advance(time.Second * 8)
+	assert.NoError(push(pc, "new", epoch.Add(time.Second*9)))
+
+	// 8초: 유효 우선순위로 힙을 재구성한다(older 3초, old 4초, new 9초)
+	item, err := pc.PopItem()
+	assert.NoError(err)
+	assert.Equal("older", item.Data)
+
+	// 10초: 맨 앞의 old(마감 전) 뒤에 있는 new(마감 후)도 만료된다
+	fake.Advance(time.Second * 2)
+	item, err = pc.PopItem()
+	assert.NoError(err)
+	assert.Equal("old", item.Data)
+	assert.Equal([]string{"new"}, expired)
+	assert.Zero(pc.Count())
+}
+
+// 모든 아이템이 만료되었으면 비어있는 채널과 같다
+func TestDeadlineChannelShouldBeEmptyWhenAllExpired(t *testing.T) {
+	assert := assert.New(t)
+
+	pc := NewDeadlineChannel[string](10, nil)
+	assert.NoError(push(pc, "dead", time.Now().Add(-time.Second)))
+	assert.Equal(1, pc.Count())
+
+	_, err := pc.Deque()
+	assert.Equal(ErrEmpty, err)
+	assert.Equal(0, pc.Count())
+
+	pc.Close()
+	_, err = pc.Deque()
+	assert.Equal(ErrClosed, err)
+}
+
+// Expire는 꺼내지 않아도 만료된 아이템을 정리하고 대기중인 Push를 깨운다
+func TestDeadlineChannelExpireShouldMakeRoom(t *testing.T) {
+	assert := assert.New(t)
+
+	m := NewMetrics[time.Time]("", nil)
+	fake := clock.NewFake(epoch)
+	pc := NewDeadlineChannel[string](1, nil)
+	pc.SetObserver(m)
+	pc.SetClock(fake)
+	assert.NoError(push(pc, "dying", epoch.Add(time.Millisecond*10)))
+
+	pushed := make(chan error)
+	go func() {
+		_, err := pc.Push("next", epoch.Add(time.Hour))
+		pushed <- err
+	}()
+
+	fake.Advance(time.Millisecond * 20)
+	n, err := pc.Expire()
+	assert.NoError(err)
+	assert.Equal(1, n)
+	assert.NoError(<-pushed)
+
+	assert.Equal(uint64(1), m.Expired())
+	assert.Equal(uint64(0), m.Popped())
+	assert.Equal(1, m.Depth())
+	assert.Equal(1, m.BandDepth("all"))
+}
+
+// 만료 여부를 직접 지정할 수 있다(예: 오래된 버전 번호)
+func TestPriorityChannelSetExpiryShouldUseCustomCondition(t *testing.T) {
+	assert := assert.New(t)
+
+	o := &recordObserver{}
+	pc := NewChannel[string](10)
+	pc.SetObserver(o)
+
+	version := 3
+	var stale []int
+	pc.SetExpiry(func(v int) bool { return v < version }, func(item Item[string, int]) {
+		stale = append(stale, item.Priority)
+	})
+
+	for _, v := range []int{4, 1, 3, 2} {
+		assert.NoError(push(pc, "v", v))
+	}
+	item, err := pc.PopItem()
+	assert.NoError(err)
+	assert.Equal(3, item.Priority)
+	assert.Equal([]int{1, 2}, stale)
+	assert.Contains(o.events, "expire")
+
+	// 해제
+	pc.SetExpiry(nil, nil)
+	version = 10
+	item, err = pc.PopItem()
+	assert.NoError(err)
+	assert.Equal(4, item.Priority)
+}
@@ -0,0 +1,313 @@
+package pqueue
+
+import (
+	"context"
+	"sync"
+)
+
+// 테넌트(producer) 별 공정 큐(fair queue)
+//
+// 여러 producer가 하나의 PriorityChannel을 공유하면, 아이템을 많이 넣는 producer가
+// 다른 producer의 아이템을 굶기게(starvation) 된다. FairQueue는 테넌트마다 별도의
+// PriorityChannel을 두고(테넌트 안에서는 우선순위 순서 유지), 테넌트 사이에서는
+// deficit round-robin(DRR)으로 꺼낸다.
+//
+// 아이템 비용은 1 이므로, 차례가 된 테넌트는 weight 개 까지 연속으로 꺼낼 수 있다.
+// 따라서 대기중인 아이템이 있는 테넌트는 어느 구간에서든 weight 비율 만큼 처리되며,
+// 공정 몫과의 차이는 최대 weight를 넘지 않는다(bounded unfairness).
+//
+// SetTenant로 지정하지 않은 테넌트는 대기중인 아이템이 없어지면 삭제되므로,
+// 키가 계속 바뀌어도(예: 요청 ID) 테넌트가 쌓이지 않는다. 지정한 테넌트는 RemoveTenant로 삭제한다.
+// 모든 메소드는 concurrent-safe 하다.
+type FairQueue[K comparable, T, P any] struct {
+	l       sync.Mutex
+	less    func(a, b P) bool
+	cap     int // 테넌트 기본 cap
+	weight  int // 테넌트 기본 weight
+	tenants map[K]*tenant[K, T, P]
+	active  []*tenant[K, T, P] // 대기중인 아이템이 있는 테넌트(round-robin 순서)
+	cur     int                // 현재 차례인 테넌트(active의 index)
+	count   int                // 전체 아이템 수
+	closed  bool
+	notify  chan struct{} // 상태 변경(push, pop, close) 알림
+}
+
+// 테넌트 별 큐
+type tenant[K comparable, T, P any] struct {
+	key     K
+	q       *PriorityChannel[T, P]
+	weight  int  // 한 차례에 꺼낼 수 있는 아이템 수
+	deficit int  // 이번 차례에 남은 아이템 수
+	active  bool // active 목록에 있나?
+	pinned  bool // SetTenant로 지정한 테넌트(비어도 삭제하지 않음)
+}
+
+// 공정 큐 생성
+// cap은 테넌트 별 기본 버퍼 크기이며, 기본 weight는 1 이다.
+// less(a, b)는 같은 테넌트 안에서 a가 b보다 먼저 처리되어야 하면 true를 반환한다.
+func NewFairQueue[K comparable, T, P any](cap int, less func(a, b P) bool) *FairQueue[K, T, P] {
+	return &FairQueue[K, T, P]{
+		less:    less,
+		cap:     cap,
+		weight:  1,
+		tenants: map[K]*tenant[K, T, P]{},
+	}
+}
+
+// 테넌트의 weight와 cap을 지정한다.
+// weight가 클수록 더 많이 처리되며(1 미만이면 1), 이미 입력된 아이템은 cap을 넘어도 유지된다.
+func (f *FairQueue[K, T, P]) SetTenant(key K, weight, cap int) {
+	f.l.Lock()
+	defer f.l.Unlock()
+
+	if weight < 1 {
+		weight = 1
+	}
+	t := f.tenant(key)
+	t.weight = weight
+	t.pinned = true
+	if t.deficit > weight {
+		t.deficit = weight
+	}
+	t.q.SetCap(cap)
+	f.broadcast()
+}
+
+// 테넌트를 삭제하고 대기중이던 아이템 수를 반환한다(대기중인 아이템은 버려진다).
+// 이후 같은 키로 추가하면 기본 설정의 테넌트로 다시 생성된다.
+func (f *FairQueue[K, T, P]) RemoveTenant(key K) int {
+	f.l.Lock()
+	defer f.l.Unlock()
+
+	t, ok := f.tenants[key]
+	if !ok {
+		return 0
+	}
+	n, _ := t.q.CloseNow() // 테넌트 큐는 WAL을 사용하지 않는다
+	f.count -= n
+	if t.active {
+		f.deactivate(t)
+	}
+	delete(f.tenants, key)
+	f.broadcast()
+	return n
+}
+
+// 테넌트 큐를 반환한다(없으면 기본 설정으로 생성). 락을 잡은 상태에서 호출
+func (f *FairQueue[K, T, P]) tenant(key K) *tenant[K, T, P] {
+	t, ok := f.tenants[key]
+	if !ok {
+		t = &tenant[K, T, P]{
+			key:    key,
+			q:      NewChannelFunc[T](f.cap, f.less),
+			weight: f.weight,
+		}
+		f.tenants[key] = t
+	}
+	return t
+}
+
+// 테넌트의 큐에 아이템을 추가한다(대기하지 않음)
+// 테넌트의 cap을 넘으면 ErrFull, 닫힌 큐면 ErrClosed
+func (f *FairQueue[K, T, P]) TryPush(key K, data T, priority P) error {
+	f.l.Lock()
+	defer f.l.Unlock()
+	return f.push(key, data, priority)
+}
+
+// 아이템을 추가한다. 락을 잡은 상태에서 호출
+func (f *FairQueue[K, T, P]) push(key K, data T, priority P) error {
+	if f.closed {
+		return ErrClosed
+	}
+
+	t := f.tenant(key)
+	if _, err := t.q.tryPush(data, priority); err != nil {
+		f.release(t)
+		return err
+	}
+	f.count++
+
+	// 새로 대기하는 테넌트는 round-robin의 마지막 차례
+	if !t.active {
+		t.active = true
+		t.deficit = 0
+		f.active = append(f.active, t)
+	}
+	f.broadcast()
+	return nil
+}
+
+// 테넌트의 큐에 아이템을 추가한다(테넌트의 cap에 여유가 생길 때까지 대기)
+// ctx가 종료되면 ctx.Err(), 닫힌 큐면 ErrClosed
+func (f *FairQueue[K, T, P]) Push(ctx context.Context, key K, data T, priority P) error {
+	for {
+		f.l.Lock()
+		err := f.push(key, data, priority)
+		if err != ErrFull {
+			f.l.Unlock()
+			return err
+		}
+		changed := f.changed()
+		f.l.Unlock()
+
+		// wait for tenant ready
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// 다음 차례 테넌트의 아이템을 꺼낸다(대기하지 않음)
+// 비어있으면 ErrEmpty, 닫히고 남은 아이템이 없으면 ErrClosed
+func (f *FairQueue[K, T, P]) TryPop() (key K, item Item[T, P], err error) {
+	f.l.Lock()
+	defer f.l.Unlock()
+	return f.pop()
+}
+
+// 다음 차례 테넌트의 아이템을 꺼낸다. 락을 잡은 상태에서 호출
+func (f *FairQueue[K, T, P]) pop() (key K, item Item[T, P], err error) {
+	if len(f.active) == 0 {
+		if f.closed {
+			return key, item, ErrClosed
+		}
+		return key, item, ErrEmpty
+	}
+
+	// 차례가 시작되면 weight 만큼 꺼낼 수 있다
+	t := f.active[f.cur]
+	if t.deficit == 0 {
+		t.deficit = t.weight
+	}
+
+	t.q.wLock()
+	x, err := t.q.pop()
+	t.q.wUnlock()
+	if err != nil {
+		return key, item, err
+	}
+	t.deficit--
+	f.count--
+
+	if t.q.Count() == 0 {
+		// 더 이상 대기중인 아이템이 없으면 목록에서 제거(다음 테넌트가 cur 위치로 온다)
+		f.deactivate(t)
+		f.release(t)
+	} else if t.deficit == 0 {
+		// 차례가 끝나면 다음 테넌트로
+		f.cur++
+		if f.cur >= len(f.active) {
+			f.cur = 0
+		}
+	}
+
+	f.broadcast()
+	return t.key, x.item(t.q.clock.Now()), nil
+}
+
+// 테넌트를 active 목록에서 제거한다. 락을 잡은 상태에서 호출
+// 제거한 테넌트 뒤의 테넌트는 한칸씩 앞으로 오므로 round-robin 순서가 유지된다.
+func (f *FairQueue[K, T, P]) deactivate(t *tenant[K, T, P]) {
+	for i, a := range f.active {
+		if a != t {
+			continue
+		}
+		f.active = append(f.active[:i], f.active[i+1:]...)
+		if i < f.cur {
+			f.cur--
+		}
+		break
+	}
+	if f.cur >= len(f.active) {
+		f.cur = 0
+	}
+	t.active = false
+	t.deficit = 0
+}
+
+// SetTenant로 지정하지 않은 빈 테넌트를 삭제한다. 락을 잡은 상태에서 호출
+func (f *FairQueue[K, T, P]) release(t *tenant[K, T, P]) {
+	if !t.pinned && t.q.Count() == 0 {
+		delete(f.tenants, t.key)
+	}
+}
+
+// 테넌트 수
+func (f *FairQueue[K, T, P]) Tenants() int {
+	f.l.Lock()
+	defer f.l.Unlock()
+	return len(f.tenants)
+}
+
+// 다음 차례 테넌트의 아이템을 꺼낸다(아이템이 있을때 까지 대기)
+// ctx가 종료되면 ctx.Err(), 닫히고 남은 아이템이 없으면 ErrClosed
+func (f *FairQueue[K, T, P]) Pop(ctx context.Context) (key K, item Item[T, P], err error) {
+	for {
+		f.l.Lock()
+		key, item, err = f.pop()
+		if err != ErrEmpty {
+			f.l.Unlock()
+			return key, item, err
+		}
+		changed := f.changed()
+		f.l.Unlock()
+
+		// wait for item
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return key, item, ctx.Err()
+		}
+	}
+}
+
+// 전체 아이템 수
+func (f *FairQueue[K, T, P]) Count() int {
+	f.l.Lock()
+	defer f.l.Unlock()
+	return f.count
+}
+
+// 테넌트의 아이템 수
+func (f *FairQueue[K, T, P]) TenantCount(key K) int {
+	f.l.Lock()
+	defer f.l.Unlock()
+	if t, ok := f.tenants[key]; ok {
+		return t.q.Count()
+	}
+	return 0
+}
+
+// 큐를 닫는다. 더 이상 추가할 수 없지만, 남은 아이템은 꺼낼 수 있다.
+func (f *FairQueue[K, T, P]) Close() {
+	f.l.Lock()
+	defer f.l.Unlock()
+
+	if f.closed {
+		return
+	}
+	f.closed = true
+	for _, t := range f.tenants {
+		t.q.Close()
+	}
+	f.broadcast()
+}
+
+// 상태 변경 알림 채널을 반환한다. 락을 잡은 상태에서 호출
+func (f *FairQueue[K, T, P]) changed() <-chan struct{} {
+	if f.notify == nil {
+		f.notify = make(chan struct{})
+	}
+	return f.notify
+}
+
+// 상태 변경을 기다리는 고루틴을 모두 깨운다. 락을 잡은 상태에서 호출
+func (f *FairQueue[K, T, P]) broadcast() {
+	if f.notify != nil {
+		close(f.notify)
+		f.notify = nil
+	}
+}
@@ -0,0 +1,204 @@
+package pqueue
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 테넌트 안에서는 우선순위 순서, 테넌트 사이에서는 round-robin으로 꺼낸다
+func TestFairQueueShouldKeepPriorityWithinTenant(t *testing.T) {
+	assert := assert.New(t)
+
+	f := NewFairQueue[string, string](10, Less[int])
+	assert.NoError(f.TryPush("a", "a3", 3))
+	assert.NoError(f.TryPush("a", "a1", 1))
+	assert.NoError(f.TryPush("a", "a2", 2))
+	assert.NoError(f.TryPush("b", "b5", 5))
+	assert.NoError(f.TryPush("b", "b4", 4))
+
+	var popped []string
+	for f.Count() > 0 {
+		key, item, err := f.TryPop()
+		assert.NoError(err)
+		assert.Equal(key, item.Data[:1])
+		popped = append(popped, item.Data)
+	}
+	assert.Equal([]string{"a1", "b4", "a2", "b5", "a3"}, popped)
+
+	_, _, err := f.TryPop()
+	assert.Equal(ErrEmpty, err)
+}
+
+// 아이템을 많이 넣는 테넌트가 있어도 다른 테넌트는 굶지 않는다
+func TestFairQueueShouldNotStarveQuietTenant(t *testing.T) {
+	assert := assert.New(t)
+
+	f := NewFairQueue[string, int](1000, Less[int])
+	for i := 0; i < 1000; i++ {
+		assert.NoError(f.TryPush("noisy", i, 0))
+	}
+	for i := 0; i < 10; i++ {
+		assert.NoError(f.TryPush("quiet", i, 0))
+	}
+
+	// 같은 weight: 처음 20개 중 10개가 quiet
+	quiet := 0
+	for i := 0; i < 20; i++ {
+		key, _, err := f.TryPop()
+		assert.NoError(err)
+		if key == "quiet" {
+			quiet++
+		}
+	}
+	assert.Equal(10, quiet)
+	assert.Equal(0, f.TenantCount("quiet"))
+	assert.Equal(990, f.TenantCount("noisy"))
+}
+
+// 모든 테넌트에 대기중인 아이템이 있으면, 어느 시점에서든
+// 테넌트별 처리량과 weight 비율에 따른 공정 몫의 차이는 최대 weight를 넘지 않는다
+func TestFairQueueShouldBoundUnfairnessByWeight(t *testing.T) {
+	assert := assert.New(t)
+
+	weights := map[string]int{"a": 1, "b": 3, "c": 2}
+	total, maxWeight := 0, 0
+	for _, w := range weights {
+		total += w
+		if w > maxWeight {
+			maxWeight = w
+		}
+	}
+
+	f := NewFairQueue[string, int](1000, Less[int])
+	for key, w := range weights {
+		f.SetTenant(key, w, 1000)
+	}
+	for i := 0; i < 600; i++ {
+		for key := range weights {
+			assert.NoError(f.TryPush(key, i, i))
+		}
+	}
+
+	// 가장 적게 처리되는 테넌트(a)도 끝까지 대기중인 아이템이 있는 구간만 검사
+	served := map[string]int{}
+	for n := 1; n <= 600*total/maxWeight; n++ {
+		key, item, err := f.TryPop()
+		assert.NoError(err)
+
+		// 테넌트 안에서는 입력된 우선순위 순서
+		assert.Equal(served[key], item.Data)
+		served[key]++
+
+		for key, w := range weights {
+			fair := float64(n) * float64(w) / float64(total)
+			lag := math.Abs(float64(served[key]) - fair)
+			if !assert.LessOrEqual(lag, float64(maxWeight), fmt.Sprintf("tenant %s after %d pops", key, n)) {
+				return
+			}
+		}
+	}
+}
+
+// 테넌트별 cap: 한 테넌트가 가득차도 다른 테넌트는 추가할 수 있다
+func TestFairQueueShouldLimitTenantCap(t *testing.T) {
+	assert := assert.New(t)
+
+	f := NewFairQueue[string, string](10, Less[int])
+	f.SetTenant("a", 1, 2)
+	assert.NoError(f.TryPush("a", "a1", 1))
+	assert.NoError(f.TryPush("a", "a2", 2))
+	assert.Equal(ErrFull, f.TryPush("a", "a3", 3))
+	assert.NoError(f.TryPush("b", "b1", 1))
+
+	// Push는 테넌트에 자리가 날 때까지 대기한다
+	pushed := make(chan error)
+	go func() {
+		pushed <- f.Push(context.Background(), "a", "a3", 3)
+	}()
+	select {
+	case <-pushed:
+		t.Fatal("push should block while the tenant is full")
+	case <-time.After(time.Millisecond * 20):
+	}
+
+	key, _, err := f.TryPop()
+	assert.NoError(err)
+	assert.Equal("a", key)
+	assert.NoError(<-pushed)
+	assert.Equal(2, f.TenantCount("a"))
+
+	// ctx 종료
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	assert.Equal(context.DeadlineExceeded, f.Push(ctx, "a", "a4", 4))
+}
+
+// 닫힌 큐는 남은 아이템을 꺼낸 후 ErrClosed, 대기중인 Pop도 깨어난다
+func TestFairQueueCloseShouldWakeWaiters(t *testing.T) {
+	assert := assert.New(t)
+
+	f := NewFairQueue[string, string](10, Less[int])
+	assert.NoError(f.TryPush("a", "a1", 1))
+	f.Close()
+	f.Close()
+	assert.Equal(ErrClosed, f.TryPush("a", "a2", 2))
+
+	_, item, err := f.Pop(context.Background())
+	assert.NoError(err)
+	assert.Equal("a1", item.Data)
+
+	f = NewFairQueue[string, string](10, Less[int])
+	popped := make(chan error)
+	go func() {
+		_, _, err := f.Pop(context.Background())
+		popped <- err
+	}()
+	time.Sleep(time.Millisecond * 20)
+	f.Close()
+	assert.Equal(ErrClosed, <-popped)
+}
+
+// 지정하지 않은 테넌트는 비면 삭제되고, 지정한 테넌트는 RemoveTenant로 삭제한다
+func TestFairQueueShouldRemoveIdleTenants(t *testing.T) {
+	assert := assert.New(t)
+
+	f := NewFairQueue[int, string](1, Less[int])
+	for i := 0; i < 100; i++ {
+		assert.NoError(f.TryPush(i, "x", 0))
+		_, _, err := f.TryPop()
+		assert.NoError(err)
+	}
+	assert.Zero(f.Tenants())
+
+	// cap이 0인 테넌트에 추가하지 못해도 남지 않는다
+	g := NewFairQueue[int, string](0, Less[int])
+	assert.Equal(ErrFull, g.TryPush(1, "x", 0))
+	assert.Zero(g.Tenants())
+
+	// 지정한 테넌트는 비어도 설정이 유지된다
+	f.SetTenant(1, 2, 3)
+	assert.NoError(f.TryPush(1, "a", 0))
+	assert.NoError(f.TryPush(2, "b", 0))
+	assert.NoError(f.TryPush(3, "c", 0))
+	_, _, err := f.TryPop()
+	assert.NoError(err)
+	assert.Equal(3, f.Tenants())
+
+	// 대기중인 아이템과 함께 삭제되고, 나머지 테넌트의 차례는 유지된다
+	assert.Equal(1, f.RemoveTenant(2))
+	assert.Zero(f.RemoveTenant(2))
+	assert.Equal(1, f.Count())
+	key, item, err := f.TryPop()
+	assert.NoError(err)
+	assert.Equal(3, key)
+	assert.Equal("c", item.Data)
+	assert.Equal(1, f.Tenants())
+
+	assert.Equal(0, f.RemoveTenant(1))
+	assert.Zero(f.Tenants())
+}
@@ -0,0 +1,104 @@
+package pqueue
+
+import (
+	"container/heap"
+	"errors"
+)
+
+// 채널에 없는 아이템(이미 꺼냈거나 삭제됨)
+var ErrNotQueued = errors.New("item is not queued in priority channel")
+
+// 채널에 입력된 아이템 핸들(TryPush, Push의 반환값)
+//
+// waitFor의 index 필드와 마찬가지로 힙에서의 위치를 유지하고 있으므로,
+// 이미 입력된 아이템의 우선순위 변경(heap.Fix), 삭제(heap.Remove)를 O(logN)에 처리한다.
+// 모든 메소드는 채널 락을 잡고 동작하므로 concurrent-safe 하다.
+type Handle[T, P any] struct {
+	c    *PriorityChannel[T, P]
+	item *heapItem[T, P]
+}
+
+// 아이템의 우선순위를 변경한다. O(logN)
+// 같은 우선순위 사이에서는 처음 입력된 순서를 유지한다.
+// 이미 꺼냈거나 삭제된 아이템이면 ErrNotQueued
+func (h *Handle[T, P]) Update(priority P) error {
+	h.c.wLock()
+	defer h.c.wUnlock()
+
+	if h.item.index < 0 {
+		return ErrNotQueued
+	}
+
+	prev := h.item.priority
+	h.item.priority = priority
+	if err := h.c.log(walUpdate, h.item); err != nil {
+		h.item.priority = prev
+		return err
+	}
+	h.item.effective = priority
+	if h.c.aging != nil {
+		h.item.effective = h.c.aging(priority, h.c.clock.Since(h.item.enqueuedAt))
+	}
+	heap.Fix(&h.c.q, h.item.index)
+	h.c.checkpoint()
+	h.c.observeUpdate(prev, priority)
+	return nil
+}
+
+// 아이템을 채널에서 삭제한다(작업 취소). O(logN)
+// 이미 꺼냈거나 삭제된 아이템이면 ErrNotQueued
+func (h *Handle[T, P]) Remove() error {
+	h.c.wLock()
+	defer h.c.wUnlock()
+
+	if h.item.index < 0 {
+		return ErrNotQueued
+	}
+
+	if err := h.c.log(walRemove, h.item); err != nil {
+		return err
+	}
+	heap.Remove(&h.c.q, h.item.index)
+	h.c.checkpoint()
+	h.c.observeRemove(h.item)
+	h.c.broadcast()
+	return nil
+}
+
+// 아이템이 아직 채널에서 대기중인가?
+func (h *Handle[T, P]) Queued() bool {
+	h.c.rLock()
+	defer h.c.rUnlock()
+	return h.item.index >= 0
+}
+
+// 대기중인 아이템의 정보를 반환한다(꺼내지 않음)
+// 이미 꺼냈거나 삭제된 아이템이면 ok == false
+func (h *Handle[T, P]) Peek() (item Item[T, P], ok bool) {
+	h.c.rLock()
+	defer h.c.rUnlock()
+
+	if h.item.index < 0 {
+		return item, false
+	}
+	return h.item.item(h.c.clock.Now()), true
+}
+
+// 대기중인 아이템의 현재 순서를 반환한다(0: 다음에 꺼낼 아이템). O(N)
+// 이미 꺼냈거나 삭제된 아이템이면 ok == false
+func (h *Handle[T, P]) Position() (pos int, ok bool) {
+	h.c.rLock()
+	defer h.c.rUnlock()
+
+	if h.item.index < 0 {
+		return 0, false
+	}
+
+	// 힙은 정렬되어 있지 않으므로, 앞서는 아이템의 수를 센다
+	for _, item := range h.c.q.items {
+		if h.c.q.before(item, h.item) {
+			pos++
+		}
+	}
+	return pos, true
+}
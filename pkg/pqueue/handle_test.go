@@ -0,0 +1,158 @@
+package pqueue
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"gostudy/pkg/rng"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 핸들로 대기중인 아이템의 우선순위를 변경하거나 삭제할 수 있다
+func TestHandleShouldUpdateAndRemoveQueuedItem(t *testing.T) {
+	assert := assert.New(t)
+	pc := NewChannel[string](10)
+
+	a, _ := pc.Push("a", 1)
+	b, _ := pc.Push("b", 2)
+	c, _ := pc.Push("c", 3)
+
+	pos, ok := c.Position()
+	assert.True(ok)
+	assert.Equal(2, pos)
+
+	// 환자 상태가 악화되었다
+	assert.NoError(c.Update(0))
+	pos, _ = c.Position()
+	assert.Equal(0, pos)
+	item, ok := c.Peek()
+	assert.True(ok)
+	assert.Equal("c", item.Data)
+	assert.Equal(0, item.Priority)
+
+	// 작업이 취소되었다
+	assert.NoError(a.Remove())
+	assert.False(a.Queued())
+	assert.Equal(2, pc.Count())
+	pos, _ = b.Position()
+	assert.Equal(1, pos)
+
+	for _, expected := range []string{"c", "b"} {
+		data, err := pc.Pop()
+		assert.NoError(err)
+		assert.Equal(expected, data)
+	}
+}
+
+// 우선순위를 변경해도 같은 우선순위 사이에서는 처음 입력된 순서를 유지한다
+func TestHandleUpdateShouldKeepInputOrder(t *testing.T) {
+	assert := assert.New(t)
+	pc := NewChannel[int](10)
+
+	handles := []*Handle[int, int]{}
+	for i := 0; i < 5; i++ {
+		h, err := pc.Push(i, 9-i)
+		assert.NoError(err)
+		handles = append(handles, h)
+	}
+	for _, h := range handles {
+		assert.NoError(h.Update(1))
+	}
+
+	for i := 0; i < 5; i++ {
+		data, err := pc.Pop()
+		assert.NoError(err)
+		assert.Equal(i, data)
+	}
+}
+
+// 이미 꺼냈거나 삭제된 아이템의 핸들은 ErrNotQueued를 반환한다
+func TestHandleOfPoppedItemShouldReturnErrNotQueued(t *testing.T) {
+	assert := assert.New(t)
+	pc := NewChannel[string](10)
+
+	popped, _ := pc.Push("popped", 1)
+	removed, _ := pc.Push("removed", 2)
+	discarded, _ := pc.Push("discarded", 3)
+
+	_, err := pc.Pop()
+	assert.NoError(err)
+	assert.NoError(removed.Remove())
+	n, err := pc.CloseNow()
+	assert.NoError(err)
+	assert.Equal(1, n)
+
+	for _, h := range []*Handle[string, int]{popped, removed, discarded} {
+		assert.False(h.Queued())
+		assert.ErrorIs(h.Update(0), ErrNotQueued)
+		assert.ErrorIs(h.Remove(), ErrNotQueued)
+		_, ok := h.Peek()
+		assert.False(ok)
+		_, ok = h.Position()
+		assert.False(ok)
+	}
+}
+
+// 핸들 조작과 pop이 동시에 일어나도, 모든 아이템은 정확히 한번만 꺼내지거나 삭제된다
+// go test -race 로 실행하여 data race가 없는지 확인
+func TestHandleShouldBeConcurrentSafe(t *testing.T) {
+	assert := assert.New(t)
+	count := 10000
+	pc := NewChannel[int](count)
+
+	handles := make([]*Handle[int, int], count)
+	for i := range handles {
+		h, err := pc.Push(i, rng.NextInRange(0, 100))
+		assert.NoError(err)
+		handles[i] = h
+	}
+	pc.Close()
+
+	workers := runtime.NumCPU()
+	seen := make([]int32, count)
+	var removed, popped int64
+
+	wg := sync.WaitGroup{}
+
+	// 핸들로 우선순위를 변경하거나 삭제
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func(w int) {
+			defer wg.Done()
+			for i := w; i < count; i += workers {
+				handles[i].Update(rng.NextInRange(0, 100))
+				handles[i].Position()
+				if i%3 == 0 && handles[i].Remove() == nil {
+					atomic.AddInt32(&seen[i], 1)
+					atomic.AddInt64(&removed, 1)
+				}
+			}
+		}(w)
+	}
+
+	// 동시에 pop
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for {
+				data, err := pc.Pop()
+				if err != nil {
+					return
+				}
+				atomic.AddInt32(&seen[data], 1)
+				atomic.AddInt64(&popped, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i := range seen {
+		assert.Equal(int32(1), seen[i], "item %d", i)
+	}
+	assert.Equal(int64(count), removed+popped)
+	t.Logf("removed: %d, popped: %d", removed, popped)
+}
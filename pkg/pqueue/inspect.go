@@ -0,0 +1,184 @@
+package pqueue
+
+import (
+	"container/heap"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// 채널의 맨 앞 아이템(다음에 꺼내질 아이템)을 꺼내지 않고 반환한다.
+// 비어있으면 ErrEmpty, 비어있고 닫혔으면 ErrClosed
+//
+// 조회(Peek, PeekN, Snapshot)는 채널을 변경하지 않는다. 따라서 만료 검사(SetExpiry)와
+// aging 재계산은 하지 않으며, 꺼낼 때 만료된 아이템은 제외되고 순서가 바뀔 수 있다.
+func (c *PriorityChannel[T, P]) Peek() (item Item[T, P], err error) {
+	c.rLock()
+	defer c.rUnlock()
+
+	if c.q.Len() == 0 {
+		if c.closed {
+			return item, ErrClosed
+		}
+		return item, ErrEmpty
+	}
+	return c.q.Peek().item(c.clock.Now()), nil
+}
+
+// 꺼내질 순서대로 앞에서 최대 n개의 아이템을 꺼내지 않고 반환한다. O(n logn)
+func (c *PriorityChannel[T, P]) PeekN(n int) []Item[T, P] {
+	c.rLock()
+	defer c.rUnlock()
+	return c.peekN(n)
+}
+
+// 채널에 있는 모든 아이템을 꺼내질 순서대로 복사하여 반환한다. O(N logN)
+// 하나의 락 안에서 복사하므로, 동시에 push, pop 하더라도 어느 한 시점의 일관된 상태이다.
+func (c *PriorityChannel[T, P]) Snapshot() []Item[T, P] {
+	c.rLock()
+	defer c.rUnlock()
+	return c.peekN(c.q.Len())
+}
+
+// 입력시 우선순위(Priority)별 아이템 수
+// map의 키로 사용해야 하므로 우선순위가 비교 가능(comparable)한 채널에만 사용할 수 있다.
+func CountByPriority[T any, P comparable](c *PriorityChannel[T, P]) map[P]int {
+	c.rLock()
+	defer c.rUnlock()
+
+	counts := map[P]int{}
+	for _, item := range c.q.items {
+		counts[item.priority]++
+	}
+	return counts
+}
+
+// 꺼내질 순서대로 앞에서 최대 n개의 아이템을 반환한다. 락을 잡은 상태에서 호출
+// 힙을 변경하지 않도록, root부터 꺼낸 노드의 자식들을 후보(peekHeap)에 추가하면서 가장 앞선 후보를 차례로 꺼낸다.
+func (c *PriorityChannel[T, P]) peekN(n int) []Item[T, P] {
+	if n > c.q.Len() {
+		n = c.q.Len()
+	}
+	if n <= 0 {
+		return []Item[T, P]{}
+	}
+
+	now := c.clock.Now()
+	items := make([]Item[T, P], 0, n)
+	next := &peekHeap[T, P]{q: &c.q, index: []int{0}}
+	for len(items) < n {
+		i := heap.Pop(next).(int)
+		items = append(items, c.q.items[i].item(now))
+		for _, child := range []int{2*i + 1, 2*i + 2} {
+			if child < c.q.Len() {
+				heap.Push(next, child)
+			}
+		}
+	}
+	return items
+}
+
+// priorityHeap의 index를 꺼내질 순서로 정렬하는 heap.Interface(peekN 용)
+type peekHeap[T, P any] struct {
+	q     *priorityHeap[T, P]
+	index []int
+}
+
+func (h *peekHeap[T, P]) Len() int {
+	return len(h.index)
+}
+
+func (h *peekHeap[T, P]) Less(i, j int) bool {
+	return h.q.before(h.q.items[h.index[i]], h.q.items[h.index[j]])
+}
+
+func (h *peekHeap[T, P]) Swap(i, j int) {
+	h.index[i], h.index[j] = h.index[j], h.index[i]
+}
+
+func (h *peekHeap[T, P]) Push(x interface{}) {
+	h.index = append(h.index, x.(int))
+}
+
+func (h *peekHeap[T, P]) Pop() interface{} {
+	n := len(h.index)
+	i := h.index[n-1]
+	h.index = h.index[:n-1]
+	return i
+}
+
+// 디버그용 채널 상태(JSON)
+type debugSnapshot struct {
+	Count      int            `json:"count"`
+	Cap        int            `json:"cap"`
+	Closed     bool           `json:"closed"`
+	ByPriority map[string]int `json:"by_priority"` // fmt.Sprint(priority): 아이템 수
+	Items      []debugItem    `json:"items"`       // 꺼내질 순서
+}
+
+// 디버그용 아이템 정보(JSON)
+type debugItem struct {
+	Data       any       `json:"data"`
+	Priority   any       `json:"priority"`
+	Effective  any       `json:"effective"`
+	EnqueuedAt time.Time `json:"enqueued_at"`
+	Waited     string    `json:"waited"`
+}
+
+// 채널의 스냅샷을 JSON으로 출력하는 디버그용 http.Handler
+// 쿼리 파라미터 n으로 출력할 아이템 수를 제한할 수 있다. 예: /debug/queue?n=10
+//
+//	http.Handle("/debug/queue", pc.DebugHandler())
+func (c *PriorityChannel[T, P]) DebugHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := -1
+		if s := r.URL.Query().Get("n"); s != "" {
+			v, err := strconv.Atoi(s)
+			if err != nil || v < 0 {
+				http.Error(w, "invalid n: "+s, http.StatusBadRequest)
+				return
+			}
+			n = v
+		}
+
+		body, err := json.MarshalIndent(c.debugSnapshot(n), "", "  ")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Write(body)
+	})
+}
+
+// 디버그용 채널 상태(n < 0 이면 모든 아이템)
+func (c *PriorityChannel[T, P]) debugSnapshot(n int) debugSnapshot {
+	c.rLock()
+	defer c.rUnlock()
+
+	if n < 0 {
+		n = c.q.Len()
+	}
+	snapshot := debugSnapshot{
+		Count:      c.q.Len(),
+		Cap:        c.cap,
+		Closed:     c.closed,
+		ByPriority: map[string]int{},
+		Items:      []debugItem{},
+	}
+	for _, item := range c.q.items {
+		snapshot.ByPriority[fmt.Sprint(item.priority)]++
+	}
+	for _, item := range c.peekN(n) {
+		snapshot.Items = append(snapshot.Items, debugItem{
+			Data:       item.Data,
+			Priority:   item.Priority,
+			Effective:  item.Effective,
+			EnqueuedAt: item.EnqueuedAt,
+			Waited:     item.Waited.String(),
+		})
+	}
+	return snapshot
+}
@@ -0,0 +1,169 @@
+package pqueue
+
+import (
+	"encoding/json"
+	"math/rand"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Peek는 다음에 꺼내질 아이템을 꺼내지 않고 반환한다
+func TestPriorityChannelPeekShouldNotRemove(t *testing.T) {
+	assert := assert.New(t)
+
+	pc := NewChannel[string](10)
+	_, err := pc.Peek()
+	assert.Equal(ErrEmpty, err)
+
+	before := time.Now()
+	assert.NoError(push(pc, "b", 2))
+	assert.NoError(push(pc, "a", 1))
+
+	item, err := pc.Peek()
+	assert.NoError(err)
+	assert.Equal("a", item.Data)
+	assert.Equal(1, item.Priority)
+	assert.False(item.EnqueuedAt.Before(before))
+	assert.Equal(2, pc.Count())
+
+	data, err := pc.Deque()
+	assert.NoError(err)
+	assert.Equal("a", data)
+
+	// 닫혀도 남은 아이템은 볼 수 있다
+	pc.Close()
+	item, err = pc.Peek()
+	assert.NoError(err)
+	assert.Equal("b", item.Data)
+	pc.Deque()
+	_, err = pc.Peek()
+	assert.Equal(ErrClosed, err)
+}
+
+// PeekN, Snapshot은 꺼내질 순서(우선순위, 입력 순서)와 같다
+func TestPriorityChannelPeekNShouldFollowPopOrder(t *testing.T) {
+	assert := assert.New(t)
+
+	const n = 1000
+	pc := NewChannel[int](n)
+	for i := 0; i < n; i++ {
+		assert.NoError(push(pc, i, rand.Intn(10)))
+	}
+
+	top := pc.PeekN(10)
+	snapshot := pc.Snapshot()
+	assert.Len(top, 10)
+	assert.Len(snapshot, n)
+	assert.Equal(n, pc.Count())
+	assert.Len(pc.PeekN(n*2), n)
+	assert.Empty(pc.PeekN(0))
+
+	for i, expected := range snapshot {
+		item, err := pc.PopItem()
+		assert.NoError(err)
+		assert.Equal(expected.Data, item.Data)
+		assert.Equal(expected.Priority, item.Priority)
+		assert.Equal(expected.EnqueuedAt, item.EnqueuedAt)
+		if i < len(top) {
+			assert.Equal(top[i].Data, item.Data)
+		}
+	}
+	assert.Empty(pc.Snapshot())
+}
+
+// 우선순위별 아이템 수
+func TestCountByPriority(t *testing.T) {
+	assert := assert.New(t)
+
+	pc := NewOrderedChannel[string, string](10)
+	for _, p := range []string{"high", "low", "high", "mid", "high"} {
+		assert.NoError(push(pc, p, p))
+	}
+	assert.Equal(map[string]int{"high": 3, "mid": 1, "low": 1}, CountByPriority(pc))
+
+	pc.Deque()
+	assert.Equal(2, CountByPriority(pc)["high"])
+}
+
+// 동시에 push, pop 하더라도 스냅샷은 한 시점의 정렬된 상태이다
+func TestPriorityChannelSnapshotShouldBeConsistent(t *testing.T) {
+	assert := assert.New(t)
+
+	pc := NewChannel[int](100)
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			pc.Enque(i, rand.Intn(10))
+			pc.Deque()
+			pc.Enque(i, rand.Intn(10))
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		snapshot := pc.Snapshot()
+		for j := 1; j < len(snapshot); j++ {
+			if !assert.LessOrEqual(snapshot[j-1].Priority, snapshot[j].Priority) {
+				break
+			}
+		}
+	}
+	close(done)
+	wg.Wait()
+}
+
+// 디버그 핸들러는 스냅샷을 JSON으로 출력한다
+func TestPriorityChannelDebugHandler(t *testing.T) {
+	assert := assert.New(t)
+
+	pc := NewChannel[string](10)
+	assert.NoError(push(pc, "b", 2))
+	assert.NoError(push(pc, "a", 1))
+	assert.NoError(push(pc, "a2", 1))
+
+	var snapshot struct {
+		Count      int            `json:"count"`
+		Cap        int            `json:"cap"`
+		Closed     bool           `json:"closed"`
+		ByPriority map[string]int `json:"by_priority"`
+		Items      []struct {
+			Data       string    `json:"data"`
+			Priority   int       `json:"priority"`
+			EnqueuedAt time.Time `json:"enqueued_at"`
+			Waited     string    `json:"waited"`
+		} `json:"items"`
+	}
+
+	rec := httptest.NewRecorder()
+	pc.DebugHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/debug/queue?n=2", nil))
+	assert.Equal(200, rec.Code)
+	assert.Equal("application/json; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.NoError(json.Unmarshal(rec.Body.Bytes(), &snapshot))
+
+	assert.Equal(3, snapshot.Count)
+	assert.Equal(10, snapshot.Cap)
+	assert.False(snapshot.Closed)
+	assert.Equal(map[string]int{"1": 2, "2": 1}, snapshot.ByPriority)
+	assert.Len(snapshot.Items, 2)
+	assert.Equal("a", snapshot.Items[0].Data)
+	assert.Equal("a2", snapshot.Items[1].Data)
+	assert.Equal(1, snapshot.Items[1].Priority)
+	assert.False(snapshot.Items[0].EnqueuedAt.IsZero())
+	_, err := time.ParseDuration(snapshot.Items[0].Waited)
+	assert.NoError(err)
+
+	rec = httptest.NewRecorder()
+	pc.DebugHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/debug/queue?n=x", nil))
+	assert.Equal(400, rec.Code)
+}
@@ -0,0 +1,24 @@
+package pqueue
+
+import (
+	"context"
+
+	"gostudy/pkg/queue"
+)
+
+// 채널에서 아이템을 우선순위 순으로 꺼내면서(소비) 반복한다.
+// 채널이 닫히고 남은 아이템을 모두 꺼내거나(Close, CloseNow), ctx가 종료되면 반복을 멈춘다.
+//
+//	pc.All(ctx)(func(item Item[T, P]) bool { ... })  // go 1.20
+//	for item := range pc.All(ctx) { ... }             // go 1.23+
+func (c *PriorityChannel[T, P]) All(ctx context.Context) queue.Seq[Item[T, P]] {
+	return queue.All[Item[T, P]](ctx, c)
+}
+
+// 채널의 아이템을 꺼내지 않고 꺼내질 순서대로 반복한다.
+// 반복을 시작하는 시점의 스냅샷(Snapshot)을 순회하므로, 반복중의 push, pop은 반영되지 않는다.
+func (c *PriorityChannel[T, P]) Seq() queue.Seq[Item[T, P]] {
+	return func(yield func(Item[T, P]) bool) {
+		queue.Values(c.Snapshot())(yield)
+	}
+}
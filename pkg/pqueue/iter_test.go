@@ -0,0 +1,81 @@
+package pqueue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gostudy/pkg/queue"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// All은 우선순위 순으로 꺼내면서 반복하고, 닫히고 모두 꺼내면 멈춘다
+func TestPriorityChannelAllShouldConsumeInOrderUntilClose(t *testing.T) {
+	assert := assert.New(t)
+
+	pc := NewChannel[string](10)
+	go func() {
+		for _, p := range []struct {
+			data     string
+			priority int
+		}{{"c", 3}, {"a", 1}, {"b", 2}} {
+			pc.Push(p.data, p.priority)
+		}
+		time.Sleep(time.Millisecond * 20)
+		pc.Push("d", 0)
+		pc.Close()
+	}()
+	time.Sleep(time.Millisecond * 10)
+
+	var popped []string
+	pc.All(context.Background())(func(item Item[string, int]) bool {
+		popped = append(popped, item.Data)
+		return true
+	})
+	assert.Equal([]string{"a", "b", "c", "d"}, popped)
+	assert.Equal(0, pc.Count())
+}
+
+// ctx가 종료되면 All은 멈춘다
+func TestPriorityChannelAllShouldStopOnContext(t *testing.T) {
+	assert := assert.New(t)
+
+	pc := NewChannel[string](10)
+	assert.NoError(push(pc, "a", 1))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*20)
+	defer cancel()
+	items := queue.Collect(pc.All(ctx))
+	assert.Len(items, 1)
+	assert.Equal(context.DeadlineExceeded, ctx.Err())
+}
+
+// Seq는 꺼내지 않고 반복을 시작하는 시점의 스냅샷을 순회한다
+func TestPriorityChannelSeqShouldWalkSnapshot(t *testing.T) {
+	assert := assert.New(t)
+
+	pc := NewChannel[string](10)
+	assert.NoError(push(pc, "b", 2))
+	seq := pc.Seq()
+	assert.NoError(push(pc, "a", 1))
+
+	var walked []string
+	queue.Enumerate(seq)(func(i int, item Item[string, int]) bool {
+		walked = append(walked, item.Data)
+		// 반복중에 추가, 삭제해도 스냅샷에는 반영되지 않는다
+		pc.Deque()
+		pc.Enque("c", 0)
+		return true
+	})
+	assert.Equal([]string{"a", "b"}, walked)
+	assert.Equal(2, pc.Count())
+
+	// 중단
+	walked = nil
+	seq(func(item Item[string, int]) bool {
+		walked = append(walked, item.Data)
+		return false
+	})
+	assert.Equal([]string{"c"}, walked)
+}
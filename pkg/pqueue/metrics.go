@@ -0,0 +1,443 @@
+package pqueue
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 대기 시간 히스토그램의 기본 버킷(초)
+var DefaultWaitBuckets = []float64{.001, .005, .01, .05, .1, .5, 1, 5, 10, 30, 60}
+
+// 메모리 히스토그램
+// 값을 버킷(상한값) 별로 세어 두고, 평균이나 분위수(quantile)를 근사한다.
+// 모든 메소드는 concurrent-safe 하다.
+type Histogram struct {
+	l      sync.Mutex
+	bounds []float64 // 버킷 상한값(오름차순)
+	counts []uint64  // 버킷별 개수(마지막: +Inf)
+	sum    float64
+	count  uint64
+}
+
+// 히스토그램 생성. bounds가 없으면 DefaultWaitBuckets를 사용한다.
+func NewHistogram(bounds ...float64) *Histogram {
+	if len(bounds) == 0 {
+		bounds = DefaultWaitBuckets
+	}
+	bounds = append([]float64(nil), bounds...)
+	sort.Float64s(bounds)
+	return &Histogram{
+		bounds: bounds,
+		counts: make([]uint64, len(bounds)+1),
+	}
+}
+
+// 값을 기록한다.
+func (h *Histogram) Observe(v float64) {
+	h.l.Lock()
+	defer h.l.Unlock()
+
+	// v 이상인 첫 상한값의 버킷(le: less or equal)
+	i := sort.SearchFloat64s(h.bounds, v)
+	h.counts[i]++
+	h.sum += v
+	h.count++
+}
+
+// 기록된 값의 수
+func (h *Histogram) Count() uint64 {
+	h.l.Lock()
+	defer h.l.Unlock()
+	return h.count
+}
+
+// 기록된 값의 합
+func (h *Histogram) Sum() float64 {
+	h.l.Lock()
+	defer h.l.Unlock()
+	return h.sum
+}
+
+// 기록된 값의 평균(기록된 값이 없으면 0)
+func (h *Histogram) Mean() float64 {
+	h.l.Lock()
+	defer h.l.Unlock()
+	if h.count == 0 {
+		return 0
+	}
+	return h.sum / float64(h.count)
+}
+
+// q 분위수(0 <= q <= 1)를 근사한다.
+// Prometheus의 histogram_quantile과 같이 해당 버킷 안에서 선형 보간한다.
+// 마지막(+Inf) 버킷에 해당하면 가장 큰 상한값을 반환한다.
+func (h *Histogram) Quantile(q float64) float64 {
+	h.l.Lock()
+	defer h.l.Unlock()
+
+	if h.count == 0 {
+		return math.NaN()
+	}
+	q = math.Max(0, math.Min(q, 1))
+
+	rank := q * float64(h.count)
+	var cumulative uint64
+	for i, n := range h.counts {
+		if n == 0 || float64(cumulative+n) < rank {
+			cumulative += n
+			continue
+		}
+		if i == len(h.bounds) {
+			return h.bounds[len(h.bounds)-1]
+		}
+		lower := 0.0
+		if i > 0 {
+			lower = h.bounds[i-1]
+		}
+		upper := h.bounds[i]
+		return lower + (upper-lower)*(rank-float64(cumulative))/float64(n)
+	}
+	return h.bounds[len(h.bounds)-1]
+}
+
+// 버킷 상한값과 누적 개수(Prometheus의 le 버킷)를 반환한다.
+// cumulative의 마지막 값은 +Inf 버킷(= Count)이다.
+func (h *Histogram) Buckets() (bounds []float64, cumulative []uint64) {
+	h.l.Lock()
+	defer h.l.Unlock()
+
+	bounds = append([]float64(nil), h.bounds...)
+	cumulative = make([]uint64, len(h.counts))
+	var sum uint64
+	for i, n := range h.counts {
+		sum += n
+		cumulative[i] = sum
+	}
+	return bounds, cumulative
+}
+
+// 우선순위 구간(band)의 이름을 반환하는 함수를 만든다.
+// bounds는 오름차순이고, names는 len(bounds)+1 개 이다.
+// 예: PriorityBands([]int{30, 60}, "critical", "urgent", "minor") // p < 30: critical, p < 60: urgent, 그외: minor
+func PriorityBands[P ordered](bounds []P, names ...string) func(P) string {
+	if len(names) != len(bounds)+1 {
+		panic("PriorityBands: len(names) must be len(bounds)+1")
+	}
+	return func(p P) string {
+		for i, bound := range bounds {
+			if p < bound {
+				return names[i]
+			}
+		}
+		return names[len(bounds)]
+	}
+}
+
+// 우선순위 구간(band) 별 메트릭
+type bandMetrics struct {
+	depth int        // 대기중인 아이템 수
+	wait  *Histogram // 대기 시간(초)
+}
+
+// 우선순위 채널 메트릭(Observer 구현)
+//
+// 처리량(push, pop, reject, remove, evict, expire 카운터), 채널 깊이, 우선순위 구간(band) 별 깊이와
+// 대기 시간 히스토그램을 수집하고, Prometheus text format으로 내보낸다(http.Handler).
+//
+//	m := NewMetrics("er", PriorityBands([]int{30, 60}, "critical", "urgent", "minor"))
+//	pc.SetObserver(m)
+//	http.Handle("/metrics", m)
+type Metrics[P any] struct {
+	l        sync.Mutex
+	name     string         // queue 라벨(빈 문자열: 라벨 없음)
+	band     func(P) string // 우선순위 -> band 이름
+	buckets  []float64      // 대기 시간 히스토그램 버킷
+	pushed   uint64
+	popped   uint64
+	removed  uint64
+	evicted  uint64
+	expired  uint64
+	rejected map[string]uint64 // reason(full, closed, dropped) 별 거절 수
+	depth    int
+	closed   bool
+	bands    map[string]*bandMetrics
+}
+
+// 메트릭 생성
+// band가 nil이면 모든 우선순위를 "all" band로 모은다.
+// buckets가 없으면 DefaultWaitBuckets를 사용한다.
+func NewMetrics[P any](name string, band func(P) string, buckets ...float64) *Metrics[P] {
+	if band == nil {
+		band = func(P) string { return "all" }
+	}
+	return &Metrics[P]{
+		name:     name,
+		band:     band,
+		buckets:  buckets,
+		rejected: map[string]uint64{},
+		bands:    map[string]*bandMetrics{},
+	}
+}
+
+// band 메트릭을 반환한다(없으면 생성). 락을 잡은 상태에서 호출
+func (m *Metrics[P]) bandOf(priority P) *bandMetrics {
+	name := m.band(priority)
+	b, ok := m.bands[name]
+	if !ok {
+		b = &bandMetrics{wait: NewHistogram(m.buckets...)}
+		m.bands[name] = b
+	}
+	return b
+}
+
+func (m *Metrics[P]) OnPush(priority P, depth int) {
+	m.l.Lock()
+	defer m.l.Unlock()
+	m.pushed++
+	m.depth = depth
+	m.bandOf(priority).depth++
+}
+
+func (m *Metrics[P]) OnPop(priority P, waited time.Duration, depth int) {
+	m.l.Lock()
+	defer m.l.Unlock()
+	m.popped++
+	m.depth = depth
+	b := m.bandOf(priority)
+	b.depth--
+	b.wait.Observe(waited.Seconds())
+}
+
+func (m *Metrics[P]) OnReject(priority P, err error) {
+	m.l.Lock()
+	defer m.l.Unlock()
+	m.rejected[rejectReason(err)]++
+}
+
+// 거절 사유 라벨
+func rejectReason(err error) string {
+	switch err {
+	case ErrClosed:
+		return "closed"
+	case ErrDropped:
+		return "dropped"
+	}
+	return "full"
+}
+
+func (m *Metrics[P]) OnRemove(priority P, depth int) {
+	m.l.Lock()
+	defer m.l.Unlock()
+	m.removed++
+	m.depth = depth
+	m.bandOf(priority).depth--
+}
+
+func (m *Metrics[P]) OnEvict(priority P, depth int) {
+	m.l.Lock()
+	defer m.l.Unlock()
+	m.evicted++
+	m.depth = depth
+	m.bandOf(priority).depth--
+}
+
+func (m *Metrics[P]) OnExpire(priority P, depth int) {
+	m.l.Lock()
+	defer m.l.Unlock()
+	m.expired++
+	m.depth = depth
+	m.bandOf(priority).depth--
+}
+
+func (m *Metrics[P]) OnUpdate(from, to P) {
+	m.l.Lock()
+	defer m.l.Unlock()
+	m.bandOf(from).depth--
+	m.bandOf(to).depth++
+}
+
+func (m *Metrics[P]) OnClose(depth int) {
+	m.l.Lock()
+	defer m.l.Unlock()
+	m.closed = true
+	m.depth = depth
+	// CloseNow: 남은 아이템을 모두 버렸다
+	if depth == 0 {
+		for _, b := range m.bands {
+			b.depth = 0
+		}
+	}
+}
+
+// 추가된 아이템 수
+func (m *Metrics[P]) Pushed() uint64 {
+	m.l.Lock()
+	defer m.l.Unlock()
+	return m.pushed
+}
+
+// 꺼낸 아이템 수
+func (m *Metrics[P]) Popped() uint64 {
+	m.l.Lock()
+	defer m.l.Unlock()
+	return m.popped
+}
+
+// 거절된 추가 요청 수(ErrFull, ErrClosed, ErrDropped)
+func (m *Metrics[P]) Rejected(err error) uint64 {
+	m.l.Lock()
+	defer m.l.Unlock()
+	return m.rejected[rejectReason(err)]
+}
+
+// 오버플로 정책에 따라 버려진 대기중인 아이템 수
+func (m *Metrics[P]) Evicted() uint64 {
+	m.l.Lock()
+	defer m.l.Unlock()
+	return m.evicted
+}
+
+// 만료되어 버려진 아이템 수
+func (m *Metrics[P]) Expired() uint64 {
+	m.l.Lock()
+	defer m.l.Unlock()
+	return m.expired
+}
+
+// 대기중인 아이템 수
+func (m *Metrics[P]) Depth() int {
+	m.l.Lock()
+	defer m.l.Unlock()
+	return m.depth
+}
+
+// band의 대기중인 아이템 수
+func (m *Metrics[P]) BandDepth(band string) int {
+	m.l.Lock()
+	defer m.l.Unlock()
+	if b, ok := m.bands[band]; ok {
+		return b.depth
+	}
+	return 0
+}
+
+// band의 대기 시간(초) 히스토그램(없으면 nil)
+func (m *Metrics[P]) Wait(band string) *Histogram {
+	m.l.Lock()
+	defer m.l.Unlock()
+	if b, ok := m.bands[band]; ok {
+		return b.wait
+	}
+	return nil
+}
+
+// Prometheus text format(0.0.4)으로 메트릭을 출력한다.
+func (m *Metrics[P]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.WriteTo(w)
+}
+
+// Prometheus text format(0.0.4)으로 메트릭을 출력한다.
+func (m *Metrics[P]) WriteTo(w io.Writer) (int64, error) {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	var sb strings.Builder
+	metric := func(name, typ, help string) {
+		fmt.Fprintf(&sb, "# HELP priority_channel_%s %s\n", name, help)
+		fmt.Fprintf(&sb, "# TYPE priority_channel_%s %s\n", name, typ)
+	}
+	sample := func(name string, value float64, labels ...string) {
+		fmt.Fprintf(&sb, "priority_channel_%s%s %s\n", name, m.labels(labels...), formatFloat(value))
+	}
+
+	// band 이름 순으로 출력
+	bands := make([]string, 0, len(m.bands))
+	for name := range m.bands {
+		bands = append(bands, name)
+	}
+	sort.Strings(bands)
+
+	metric("pushed_total", "counter", "Total number of items pushed.")
+	sample("pushed_total", float64(m.pushed))
+	metric("popped_total", "counter", "Total number of items popped.")
+	sample("popped_total", float64(m.popped))
+	metric("removed_total", "counter", "Total number of items removed by handle.")
+	sample("removed_total", float64(m.removed))
+	metric("evicted_total", "counter", "Total number of queued items evicted by overflow policy.")
+	sample("evicted_total", float64(m.evicted))
+	metric("expired_total", "counter", "Total number of queued items expired before pop.")
+	sample("expired_total", float64(m.expired))
+	metric("rejected_total", "counter", "Total number of rejected pushes by reason.")
+	sample("rejected_total", float64(m.rejected["full"]), "reason", "full")
+	sample("rejected_total", float64(m.rejected["closed"]), "reason", "closed")
+	sample("rejected_total", float64(m.rejected["dropped"]), "reason", "dropped")
+
+	metric("depth", "gauge", "Number of items waiting in the channel.")
+	sample("depth", float64(m.depth))
+	metric("band_depth", "gauge", "Number of items waiting in the channel by priority band.")
+	for _, name := range bands {
+		sample("band_depth", float64(m.bands[name].depth), "band", name)
+	}
+	metric("closed", "gauge", "Whether the channel is closed.")
+	closed := 0.0
+	if m.closed {
+		closed = 1
+	}
+	sample("closed", closed)
+
+	metric("wait_seconds", "histogram", "Time items waited in the channel by priority band.")
+	for _, name := range bands {
+		wait := m.bands[name].wait
+		bounds, cumulative := wait.Buckets()
+		for i, bound := range bounds {
+			sample("wait_seconds_bucket", float64(cumulative[i]), "band", name, "le", formatFloat(bound))
+		}
+		sample("wait_seconds_bucket", float64(cumulative[len(bounds)]), "band", name, "le", "+Inf")
+		sample("wait_seconds_sum", wait.Sum(), "band", name)
+		sample("wait_seconds_count", float64(wait.Count()), "band", name)
+	}
+
+	n, err := io.WriteString(w, sb.String())
+	return int64(n), err
+}
+
+// {queue="name",k="v",...} 형식의 라벨 문자열. 락을 잡은 상태에서 호출
+func (m *Metrics[P]) labels(kv ...string) string {
+	if m.name != "" {
+		kv = append([]string{"queue", m.name}, kv...)
+	}
+	if len(kv) == 0 {
+		return ""
+	}
+
+	pairs := make([]string, 0, len(kv)/2)
+	for i := 0; i+1 < len(kv); i += 2 {
+		pairs = append(pairs, kv[i]+`="`+labelEscaper.Replace(kv[i+1])+`"`)
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// Prometheus text format의 라벨 값 escape
+// strconv.Quote와 달리 역슬래시, 큰따옴표, 줄바꿈만 escape 하고 나머지(유니코드, 제어 문자)는 그대로 쓴다.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// Prometheus 형식의 숫자 문자열
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,259 @@
+package pqueue
+
+import (
+	"context"
+	"io"
+	"math"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 호출된 observer 이벤트를 기록한다
+type recordObserver struct {
+	events []string
+}
+
+func (o *recordObserver) OnPush(priority int, depth int) {
+	o.events = append(o.events, "push", strconv.Itoa(priority), strconv.Itoa(depth))
+}
+
+func (o *recordObserver) OnPop(priority int, waited time.Duration, depth int) {
+	o.events = append(o.events, "pop", strconv.Itoa(priority), strconv.Itoa(depth))
+}
+
+func (o *recordObserver) OnReject(priority int, err error) {
+	o.events = append(o.events, "reject", strconv.Itoa(priority), err.Error())
+}
+
+func (o *recordObserver) OnRemove(priority int, depth int) {
+	o.events = append(o.events, "remove", strconv.Itoa(priority), strconv.Itoa(depth))
+}
+
+func (o *recordObserver) OnEvict(priority int, depth int) {
+	o.events = append(o.events, "evict", strconv.Itoa(priority), strconv.Itoa(depth))
+}
+
+func (o *recordObserver) OnExpire(priority int, depth int) {
+	o.events = append(o.events, "expire", strconv.Itoa(priority), strconv.Itoa(depth))
+}
+
+func (o *recordObserver) OnUpdate(from, to int) {
+	o.events = append(o.events, "update", strconv.Itoa(from), strconv.Itoa(to))
+}
+
+func (o *recordObserver) OnClose(depth int) {
+	o.events = append(o.events, "close", strconv.Itoa(depth))
+}
+
+// 채널은 push, pop, reject, remove, update, close를 observer에 알린다
+func TestPriorityChannelShouldNotifyObserver(t *testing.T) {
+	assert := assert.New(t)
+
+	pc := NewChannel[string](2)
+	o := &recordObserver{}
+	pc.SetObserver(o)
+
+	h, _ := pc.TryPush("a", 5)
+	pc.TryPush("b", 1)
+	pc.TryPush("c", 3) // full
+	assert.NoError(h.Update(7))
+	pc.Deque()
+	assert.NoError(h.Remove())
+	pc.Close()
+	pc.Close() // 여러번 닫아도 한번만 알린다
+	pc.Enque("d", 2)
+
+	assert.Equal([]string{
+		"push", "5", "1",
+		"push", "1", "2",
+		"reject", "3", ErrFull.Error(),
+		"update", "5", "7",
+		"pop", "1", "1",
+		"remove", "7", "0",
+		"close", "0",
+		"reject", "2", ErrClosed.Error(),
+	}, o.events)
+}
+
+// 대기하는 Push의 ErrFull은 거절이 아니다
+func TestPriorityChannelBlockingPushShouldNotReportReject(t *testing.T) {
+	assert := assert.New(t)
+
+	pc := NewChannel[string](1)
+	pc.SetTick(time.Millisecond)
+	m := NewMetrics[int]("", nil)
+	pc.SetObserver(m)
+
+	assert.NoError(push(pc, "a", 1))
+	done := make(chan error)
+	go func() {
+		_, err := pc.Push("b", 2)
+		done <- err
+	}()
+
+	time.Sleep(time.Millisecond * 20)
+	pc.Deque()
+	assert.NoError(<-done)
+	assert.Equal(uint64(0), m.Rejected(ErrFull))
+	assert.Equal(uint64(2), m.Pushed())
+
+	// 닫힌 채널에 대한 Push는 거절
+	pc.Close()
+	_, err := pc.Push("c", 3)
+	assert.Equal(ErrClosed, err)
+	assert.Equal(uint64(1), m.Rejected(ErrClosed))
+}
+
+// 히스토그램은 버킷 안에서 선형 보간하여 분위수를 근사한다
+func TestHistogramQuantile(t *testing.T) {
+	assert := assert.New(t)
+
+	h := NewHistogram(1, 2, 4)
+	assert.True(math.IsNaN(h.Quantile(0.5)))
+
+	for _, v := range []float64{0.5, 0.5, 1.5, 1.5, 3, 3, 3, 3} {
+		h.Observe(v)
+	}
+	assert.Equal(uint64(8), h.Count())
+	assert.Equal(16.0, h.Sum())
+	assert.Equal(2.0, h.Mean())
+
+	assert.Equal(1.0, h.Quantile(0.25))
+	assert.Equal(2.0, h.Quantile(0.5))
+	assert.Equal(3.0, h.Quantile(0.75))
+	assert.Equal(4.0, h.Quantile(1))
+
+	bounds, cumulative := h.Buckets()
+	assert.Equal([]float64{1, 2, 4}, bounds)
+	assert.Equal([]uint64{2, 4, 8, 8}, cumulative)
+
+	// 가장 큰 상한값을 넘는 값은 +Inf 버킷
+	h.Observe(100)
+	assert.Equal(4.0, h.Quantile(1))
+}
+
+// band 별 깊이 게이지는 push, pop, update, remove, CloseNow를 반영한다
+func TestMetricsShouldTrackDepthByBand(t *testing.T) {
+	assert := assert.New(t)
+
+	pc := NewChannel[string](10)
+	m := NewMetrics("er", PriorityBands([]int{30, 60}, "critical", "urgent", "minor"))
+	pc.SetObserver(m)
+
+	h, _ := pc.TryPush("a", 10)
+	pc.TryPush("b", 20)
+	pc.TryPush("c", 40)
+	pc.TryPush("d", 90)
+	assert.Equal(4, m.Depth())
+	assert.Equal(2, m.BandDepth("critical"))
+	assert.Equal(1, m.BandDepth("urgent"))
+	assert.Equal(1, m.BandDepth("minor"))
+
+	assert.NoError(h.Update(50))
+	assert.Equal(1, m.BandDepth("critical"))
+	assert.Equal(2, m.BandDepth("urgent"))
+
+	pc.Deque() // b
+	assert.Equal(0, m.BandDepth("critical"))
+	assert.Equal(uint64(1), m.Wait("critical").Count())
+	assert.Nil(m.Wait("unknown"))
+
+	assert.NoError(h.Remove())
+	assert.Equal(1, m.BandDepth("urgent"))
+	assert.Equal(2, m.Depth())
+
+	pc.CloseNow()
+	assert.Equal(0, m.Depth())
+	assert.Equal(0, m.BandDepth("urgent"))
+	assert.Equal(0, m.BandDepth("minor"))
+}
+
+// Out으로 전달하지 못하고 되돌린 아이템도 깊이에 반영된다
+func TestMetricsShouldTrackRestoredItems(t *testing.T) {
+	assert := assert.New(t)
+
+	pc := NewChannel[string](10)
+	m := NewMetrics[int]("", nil)
+	pc.SetObserver(m)
+	assert.NoError(push(pc, "a", 1))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	out := pc.Out(ctx)
+	time.Sleep(time.Millisecond * 20) // pump가 아이템을 꺼낼 때까지 대기
+	cancel()
+	for range out {
+	}
+
+	assert.Equal(1, m.Depth())
+	assert.Equal(1, m.BandDepth("all"))
+	assert.Equal(1, pc.Count())
+}
+
+// Prometheus text format으로 메트릭을 출력한다
+func TestMetricsShouldServePrometheusTextFormat(t *testing.T) {
+	assert := assert.New(t)
+
+	pc := NewChannel[string](1)
+	m := NewMetrics("er", PriorityBands([]int{50}, "high", "low"), 0.5, 1)
+	pc.SetObserver(m)
+
+	pc.TryPush("a", 10)
+	pc.TryPush("b", 90) // full
+	pc.Deque()
+	pc.TryPush("c", 90)
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Equal(200, rec.Code)
+	assert.True(strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain; version=0.0.4"))
+
+	body, _ := io.ReadAll(rec.Body)
+	text := string(body)
+	for _, line := range []string{
+		"# TYPE priority_channel_pushed_total counter",
+		`priority_channel_pushed_total{queue="er"} 2`,
+		`priority_channel_popped_total{queue="er"} 1`,
+		`priority_channel_rejected_total{queue="er",reason="full"} 1`,
+		`priority_channel_rejected_total{queue="er",reason="closed"} 0`,
+		"# TYPE priority_channel_depth gauge",
+		`priority_channel_depth{queue="er"} 1`,
+		`priority_channel_band_depth{queue="er",band="high"} 0`,
+		`priority_channel_band_depth{queue="er",band="low"} 1`,
+		`priority_channel_closed{queue="er"} 0`,
+		"# TYPE priority_channel_wait_seconds histogram",
+		`priority_channel_wait_seconds_bucket{queue="er",band="high",le="0.5"} 1`,
+		`priority_channel_wait_seconds_bucket{queue="er",band="high",le="+Inf"} 1`,
+		`priority_channel_wait_seconds_count{queue="er",band="high"} 1`,
+		`priority_channel_wait_seconds_count{queue="er",band="low"} 0`,
+	} {
+		assert.Contains(text, line+"\n")
+	}
+
+	// band 이름 순으로 출력한다
+	assert.Less(strings.Index(text, `band="high"`), strings.Index(text, `band="low"`))
+}
+
+// 라벨 값은 \\, \", \n 만 escape 한다(Prometheus text format)
+func TestMetricsShouldEscapeLabelValues(t *testing.T) {
+	assert := assert.New(t)
+
+	pc := NewChannel[string](10)
+	m := NewMetrics("응급실\t\"er\"", func(p int) string { return "c:\\중증\n" })
+	pc.SetObserver(m)
+	pc.TryPush("a", 1)
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+	text := string(body)
+
+	assert.Contains(text, "priority_channel_band_depth{queue=\"응급실\t\\\"er\\\"\",band=\"c:\\\\중증\\n\"} 1\n")
+	assert.NotContains(text, `\u`)
+	assert.NotContains(text, `\x`)
+	assert.NotContains(text, `\t`)
+}
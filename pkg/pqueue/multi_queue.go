@@ -0,0 +1,201 @@
+package pqueue
+
+import (
+	"container/heap"
+	"math/rand"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"gostudy/pkg/clock"
+)
+
+// 우선순위 큐 공통 인터페이스(대기하지 않는 연산)
+// PriorityChannel(엄격한 순서)과 MultiQueue(완화된 순서)가 구현한다.
+type PriorityQueue[T, P any] interface {
+	// 아이템을 추가한다. 가득찼으면 ErrFull, 닫혔으면 ErrClosed
+	Enque(data T, priority P) error
+	// 아이템을 꺼낸다. 비어있으면 ErrEmpty, 닫히고 비어있으면 ErrClosed
+	Deque() (T, error)
+	// 아이템 수
+	Count() int
+	// 더 이상 추가할 수 없게 닫는다(남은 아이템은 꺼낼 수 있다)
+	Close()
+}
+
+// 컴파일 타임에 PriorityQueue 구현 여부 검사
+var (
+	_ PriorityQueue[int, int] = (*PriorityChannel[int, int])(nil)
+	_ PriorityQueue[int, int] = (*MultiQueue[int, int])(nil)
+)
+
+// MultiQueue의 샤드(락 + 힙)
+type mqShard[T, P any] struct {
+	l   sync.Mutex
+	q   priorityHeap[T, P]
+	top atomic.Pointer[heapItem[T, P]] // 맨 앞 아이템(락 없이 비교하기 위함, 비어있으면 nil)
+	_   [64]byte                       // false sharing 방지(cache line padding)
+}
+
+// 맨 앞 아이템을 갱신한다. 락을 잡은 상태에서 호출
+func (s *mqShard[T, P]) updateTop() {
+	if s.q.Len() == 0 {
+		s.top.Store(nil)
+		return
+	}
+	s.top.Store(s.q.Peek())
+}
+
+// 샤드에서 아이템을 꺼낸다. 락을 잡은 상태에서 호출
+func (s *mqShard[T, P]) pop() *heapItem[T, P] {
+	item := heap.Pop(&s.q).(*heapItem[T, P])
+	s.updateTop()
+	return item
+}
+
+// 완화된(relaxed) 순서의 concurrent 우선순위 큐(MultiQueue)
+//
+// PriorityChannel은 하나의 락으로 힙 전체를 보호하므로, 코어가 많아지면 락 경합으로 처리량이 떨어진다.
+// MultiQueue는 k개의 힙(샤드)에 아이템을 나눠 담는다.
+//   - push: 임의의 샤드에 추가한다(락을 잡지 못하면 다른 샤드를 시도).
+//   - pop: 임의의 두 샤드의 맨 앞 아이템을 락 없이 비교하고, 더 앞선 샤드에서 꺼낸다(two-choice).
+//
+// 따라서 항상 가장 앞선 아이템을 꺼내지는 않지만(순서 오류), 꺼낸 아이템의 순위 오차(rank error)는
+// 평균적으로 O(k) 이다. 엄격한 순서가 필요하면 PriorityChannel을 사용한다.
+// 대기하는(blocking) 연산은 제공하지 않는다. 모든 메소드는 concurrent-safe 하다.
+type MultiQueue[T, P any] struct {
+	shards []*mqShard[T, P]
+	order  priorityHeap[T, P] // 우선순위 비교용(아이템 없음)
+	cap    int64
+	clock  clock.Clock   // 입력 시간을 기록할 시계
+	count  atomic.Int64  // 아이템 수(추가중인 아이템 포함)
+	seq    atomic.Uint64 // 입력 순서 카운터
+	closed atomic.Bool
+}
+
+// MultiQueue 생성
+// shards가 0 이하면 GOMAXPROCS * 4 개의 샤드를 사용한다. shards가 1이면 PriorityChannel과 같은 순서로 꺼낸다.
+// less(a, b)는 a가 b보다 먼저 처리되어야 하면 true를 반환한다.
+func NewMultiQueue[T, P any](cap, shards int, less func(a, b P) bool) *MultiQueue[T, P] {
+	return NewMultiQueueWithClock[T](cap, shards, less, clock.Real)
+}
+
+// 입력 시간을 c로 기록하는 MultiQueue 생성
+// 락 없이 시계를 읽으므로 PriorityChannel.SetClock과 달리 생성할 때 지정한다.
+func NewMultiQueueWithClock[T, P any](cap, shards int, less func(a, b P) bool, c clock.Clock) *MultiQueue[T, P] {
+	if shards <= 0 {
+		shards = runtime.GOMAXPROCS(0) * 4
+	}
+
+	mq := &MultiQueue[T, P]{
+		shards: make([]*mqShard[T, P], shards),
+		order:  priorityHeap[T, P]{less: less},
+		cap:    int64(cap),
+		clock:  c,
+	}
+	for i := range mq.shards {
+		mq.shards[i] = &mqShard[T, P]{q: priorityHeap[T, P]{less: less}}
+	}
+	return mq
+}
+
+// 아이템을 추가한다(대기하지 않음)
+// 가득찼으면 ErrFull, 닫혔으면 ErrClosed
+func (mq *MultiQueue[T, P]) Enque(data T, priority P) error {
+	if mq.closed.Load() {
+		return ErrClosed
+	}
+
+	// 자리를 먼저 예약한다
+	if mq.count.Add(1) > mq.cap {
+		mq.count.Add(-1)
+		return ErrFull
+	}
+
+	item := &heapItem[T, P]{
+		element:    element[T, P]{data: data, priority: priority},
+		effective:  priority,
+		enqueuedAt: mq.clock.Now(),
+		seq:        mq.seq.Add(1),
+	}
+
+	// 락을 잡을 수 있는 임의의 샤드에 추가(모두 실패하면 대기)
+	k := len(mq.shards)
+	s := mq.shards[rand.Intn(k)]
+	locked := s.l.TryLock()
+	for i := 0; i < k && !locked; i++ {
+		s = mq.shards[rand.Intn(k)]
+		locked = s.l.TryLock()
+	}
+	if !locked {
+		s.l.Lock()
+	}
+	heap.Push(&s.q, item)
+	s.updateTop()
+	s.l.Unlock()
+	return nil
+}
+
+// 아이템을 꺼낸다(대기하지 않음)
+// 임의의 두 샤드 중 맨 앞 아이템이 더 앞선 샤드에서 꺼낸다.
+// 비어있으면 ErrEmpty, 닫히고 비어있으면 ErrClosed
+func (mq *MultiQueue[T, P]) Deque() (data T, err error) {
+	k := len(mq.shards)
+	for attempt := 0; attempt < k && mq.count.Load() > 0; attempt++ {
+		a, b := mq.shards[rand.Intn(k)], mq.shards[rand.Intn(k)]
+		ta, tb := a.top.Load(), b.top.Load()
+		if ta == nil || (tb != nil && mq.order.before(tb, ta)) {
+			a, ta = b, tb
+		}
+		if ta == nil || !a.l.TryLock() {
+			continue
+		}
+		if a.q.Len() == 0 {
+			a.l.Unlock()
+			continue
+		}
+		item := a.pop()
+		a.l.Unlock()
+		mq.count.Add(-1)
+		return item.data, nil
+	}
+
+	// 두 샤드가 모두 비어있거나 경합이 심하면, 모든 샤드 중 맨 앞 아이템이 가장 앞선 샤드에서 꺼낸다
+	for mq.count.Load() > 0 {
+		var best *mqShard[T, P]
+		var top *heapItem[T, P]
+		for _, s := range mq.shards {
+			if t := s.top.Load(); t != nil && (top == nil || mq.order.before(t, top)) {
+				best, top = s, t
+			}
+		}
+		// 추가중인(자리만 예약된) 아이템만 남았다
+		if best == nil {
+			break
+		}
+
+		best.l.Lock()
+		if best.q.Len() > 0 {
+			item := best.pop()
+			best.l.Unlock()
+			mq.count.Add(-1)
+			return item.data, nil
+		}
+		best.l.Unlock()
+	}
+
+	if mq.closed.Load() {
+		return data, ErrClosed
+	}
+	return data, ErrEmpty
+}
+
+// 아이템 수
+func (mq *MultiQueue[T, P]) Count() int {
+	return int(mq.count.Load())
+}
+
+// 큐를 닫는다. 더 이상 추가할 수 없지만, 남은 아이템은 꺼낼 수 있다.
+func (mq *MultiQueue[T, P]) Close() {
+	mq.closed.Store(true)
+}
@@ -0,0 +1,226 @@
+package pqueue
+
+import (
+	"math/rand"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"gostudy/pkg/clock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 샤드가 하나면 PriorityChannel과 같은 순서(우선순위, 입력 순서)로 꺼낸다
+func TestMultiQueueWithSingleShardShouldBeStrictlyOrdered(t *testing.T) {
+	assert := assert.New(t)
+
+	mq := NewMultiQueue[string, int](10, 1, Less[int])
+	for _, p := range []struct {
+		data     string
+		priority int
+	}{{"c", 3}, {"a", 1}, {"b", 2}, {"a2", 1}} {
+		assert.NoError(mq.Enque(p.data, p.priority))
+	}
+
+	for _, expected := range []string{"a", "a2", "b", "c"} {
+		data, err := mq.Deque()
+		assert.NoError(err)
+		assert.Equal(expected, data)
+	}
+	_, err := mq.Deque()
+	assert.Equal(ErrEmpty, err)
+}
+
+// 입력 시간은 큐의 시계로 기록한다
+func TestMultiQueueShouldStampEnqueuedAtWithClock(t *testing.T) {
+	assert := assert.New(t)
+
+	fake := clock.NewFake(epoch)
+	mq := NewMultiQueueWithClock[string](10, 1, Less[int], fake)
+	assert.NoError(mq.Enque("a", 1))
+	assert.Equal(epoch, mq.shards[0].top.Load().enqueuedAt)
+}
+
+// cap, Close 규칙은 PriorityChannel과 같다
+func TestMultiQueueShouldFollowCapAndClose(t *testing.T) {
+	assert := assert.New(t)
+
+	mq := NewMultiQueue[int, int](2, 4, Less[int])
+	assert.NoError(mq.Enque(1, 1))
+	assert.NoError(mq.Enque(2, 2))
+	assert.Equal(ErrFull, mq.Enque(3, 3))
+	assert.Equal(2, mq.Count())
+
+	mq.Close()
+	assert.Equal(ErrClosed, mq.Enque(4, 4))
+
+	// 닫혀도 남은 아이템은 꺼낼 수 있다
+	var drained []int
+	for {
+		data, err := mq.Deque()
+		if err != nil {
+			assert.Equal(ErrClosed, err)
+			break
+		}
+		drained = append(drained, data)
+	}
+	assert.ElementsMatch([]int{1, 2}, drained)
+}
+
+// 여러 고루틴이 동시에 추가하고 꺼내도 모든 아이템을 정확히 한번 꺼낸다
+func TestMultiQueueShouldBeConcurrentSafe(t *testing.T) {
+	assert := assert.New(t)
+
+	const producers, consumers, n = 8, 8, 10000
+	mq := NewMultiQueue[int, int](producers*n, 0, Less[int])
+
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				assert.NoError(mq.Enque(p*n+i, rand.Intn(1000)))
+			}
+		}(p)
+	}
+
+	seen := make([]int32, producers*n)
+	var popped int64
+	var cg sync.WaitGroup
+	for c := 0; c < consumers; c++ {
+		cg.Add(1)
+		go func() {
+			defer cg.Done()
+			for atomic.LoadInt64(&popped) < producers*n {
+				data, err := mq.Deque()
+				if err != nil {
+					runtime.Gosched()
+					continue
+				}
+				atomic.AddInt32(&seen[data], 1)
+				atomic.AddInt64(&popped, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	cg.Wait()
+
+	for i := range seen {
+		if !assert.Equal(int32(1), seen[i], "item %d", i) {
+			break
+		}
+	}
+	assert.Equal(0, mq.Count())
+}
+
+// 완화된 순서: 순위 오차(rank error)의 평균은 샤드 수에 비례하는 수준이다
+func TestMultiQueueRankErrorShouldBeBoundedByShards(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, shards := range []int{1, 4, 16} {
+		mq := NewMultiQueue[int, int](10000, shards, Less[int])
+		errRate, meanRank := orderError(mq, 10000, 1)
+		t.Logf("shards=%d: order error rate=%.3f, mean rank error=%.2f", shards, errRate, meanRank)
+
+		if shards == 1 {
+			assert.Equal(0.0, errRate)
+			continue
+		}
+		assert.Less(meanRank, float64(shards*2))
+	}
+}
+
+// 0..n-1 우선순위의 아이템을 채운 뒤 workers개의 고루틴으로 모두 꺼내고, 꺼낸 순서의
+// 순서 오류율(가장 앞선 아이템이 아닌 비율)과 평균 순위 오차(더 앞선 아이템이 남아있는 수)를 반환한다.
+func orderError(q PriorityQueue[int, int], n, workers int) (errRate, meanRank float64) {
+	for _, p := range rand.Perm(n) {
+		q.Enque(p, p)
+	}
+
+	// 꺼낸 순서(ticket) 대로 기록
+	order := make([]int, n)
+	var ticket int64 = -1
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				data, err := q.Deque()
+				if err != nil {
+					return
+				}
+				order[atomic.AddInt64(&ticket, 1)] = data
+			}
+		}()
+	}
+	wg.Wait()
+
+	// fenwick tree로 남아있는 더 앞선 아이템의 수를 센다
+	tree := make([]int, n+1)
+	add := func(i, v int) {
+		for i++; i <= n; i += i & -i {
+			tree[i] += v
+		}
+	}
+	prefix := func(i int) (sum int) {
+		for ; i > 0; i -= i & -i {
+			sum += tree[i]
+		}
+		return sum
+	}
+	for i := 0; i < n; i++ {
+		add(i, 1)
+	}
+
+	errors, ranks := 0, 0
+	for _, p := range order[:ticket+1] {
+		rank := prefix(p)
+		if rank > 0 {
+			errors++
+		}
+		ranks += rank
+		add(p, -1)
+	}
+	return float64(errors) / float64(n), float64(ranks) / float64(n)
+}
+
+// 여러 고루틴에서 push, pop 하는 처리량과 순서 오류율을 함께 측정한다.
+// order-err: 가장 앞선 아이템을 꺼내지 못한 비율, rank-err: 꺼낼 때 남아있던 더 앞선 아이템 수의 평균
+func BenchmarkPriorityQueueParallel(b *testing.B) {
+	const n = 10000
+	queues := []struct {
+		name string
+		new  func() PriorityQueue[int, int]
+	}{
+		{"PriorityChannel", func() PriorityQueue[int, int] { return NewChannel[int](n * 2) }},
+		{"MultiQueue/shards=4", func() PriorityQueue[int, int] { return NewMultiQueue[int, int](n*2, 4, Less[int]) }},
+		{"MultiQueue/shards=16", func() PriorityQueue[int, int] { return NewMultiQueue[int, int](n*2, 16, Less[int]) }},
+		{"MultiQueue/shards=auto", func() PriorityQueue[int, int] { return NewMultiQueue[int, int](n*2, 0, Less[int]) }},
+	}
+
+	for _, queue := range queues {
+		b.Run(queue.name, func(b *testing.B) {
+			q := queue.new()
+			for i := 0; i < n; i++ {
+				q.Enque(i, rand.Intn(n))
+			}
+
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					q.Enque(0, rand.Intn(n))
+					q.Deque()
+				}
+			})
+			b.StopTimer()
+
+			errRate, meanRank := orderError(queue.new(), n, runtime.GOMAXPROCS(0))
+			b.ReportMetric(errRate, "order-err")
+			b.ReportMetric(meanRank, "rank-err")
+		})
+	}
+}
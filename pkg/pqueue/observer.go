@@ -0,0 +1,85 @@
+package pqueue
+
+import "time"
+
+// 우선순위 채널의 상태 변화를 관찰하는 observer
+//
+// 메트릭 수집, 로깅 등에 사용한다. SetObserver로 채널에 등록한다.
+// 모든 메소드는 채널 락을 잡은 상태에서 호출되므로(호출 순서 = 변경 순서)
+// 빨리 반환해야 하고, 채널의 메소드를 다시 호출하면 안된다(deadlock).
+type Observer[P any] interface {
+	// 아이템이 추가됨(Out의 되돌리기 포함). depth는 추가 후 채널의 아이템 수
+	OnPush(priority P, depth int)
+	// 아이템을 꺼냄. waited는 대기 시간, depth는 꺼낸 후 채널의 아이템 수
+	OnPop(priority P, waited time.Duration, depth int)
+	// 추가 실패(ErrFull, ErrClosed, ErrDropped). 대기하는 Push의 ErrFull은 거절이 아니므로 호출하지 않는다.
+	OnReject(priority P, err error)
+	// Handle.Remove로 아이템이 삭제됨. depth는 삭제 후 채널의 아이템 수
+	OnRemove(priority P, depth int)
+	// 오버플로 정책(EvictLowestPriority)에 따라 대기중인 아이템이 버려짐. depth는 버린 후 채널의 아이템 수
+	OnEvict(priority P, depth int)
+	// 만료된 아이템이 버려짐(SetExpiry). depth는 버린 후 채널의 아이템 수
+	OnExpire(priority P, depth int)
+	// Handle.Update로 아이템의 우선순위가 변경됨
+	OnUpdate(from, to P)
+	// 채널이 닫힘(Close), 혹은 남은 아이템을 버림(CloseNow). depth는 남은 아이템 수
+	OnClose(depth int)
+}
+
+// 채널에 observer를 등록한다(nil: 해제)
+// 등록 이전의 변화는 알 수 없으므로, 채널을 사용하기 전에 등록한다.
+func (c *PriorityChannel[T, P]) SetObserver(o Observer[P]) {
+	c.wLock()
+	defer c.wUnlock()
+	c.observer = o
+}
+
+// 아래 메소드는 모두 락을 잡은 상태에서 호출
+
+func (c *PriorityChannel[T, P]) observePush(item *heapItem[T, P]) {
+	if c.observer != nil {
+		c.observer.OnPush(item.priority, c.q.Len())
+	}
+}
+
+func (c *PriorityChannel[T, P]) observePop(item *heapItem[T, P]) {
+	if c.observer != nil {
+		c.observer.OnPop(item.priority, c.clock.Since(item.enqueuedAt), c.q.Len())
+	}
+}
+
+func (c *PriorityChannel[T, P]) observeReject(priority P, err error) {
+	if c.observer != nil && (err == ErrFull || err == ErrClosed || err == ErrDropped) {
+		c.observer.OnReject(priority, err)
+	}
+}
+
+func (c *PriorityChannel[T, P]) observeRemove(item *heapItem[T, P]) {
+	if c.observer != nil {
+		c.observer.OnRemove(item.priority, c.q.Len())
+	}
+}
+
+func (c *PriorityChannel[T, P]) observeEvict(item *heapItem[T, P]) {
+	if c.observer != nil {
+		c.observer.OnEvict(item.priority, c.q.Len())
+	}
+}
+
+func (c *PriorityChannel[T, P]) observeExpire(item *heapItem[T, P]) {
+	if c.observer != nil {
+		c.observer.OnExpire(item.priority, c.q.Len())
+	}
+}
+
+func (c *PriorityChannel[T, P]) observeUpdate(from, to P) {
+	if c.observer != nil {
+		c.observer.OnUpdate(from, to)
+	}
+}
+
+func (c *PriorityChannel[T, P]) observeClose() {
+	if c.observer != nil {
+		c.observer.OnClose(c.q.Len())
+	}
+}
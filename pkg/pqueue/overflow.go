@@ -0,0 +1,102 @@
+package pqueue
+
+import (
+	"container/heap"
+	"errors"
+)
+
+// 오버플로 정책에 따라 버려진 아이템
+var ErrDropped = errors.New("item dropped by overflow policy")
+
+// 채널이 가득찼을 때(len >= cap) 새 아이템을 처리하는 정책
+//
+// TryPush, Enque는 어떤 정책에서도 대기하지 않는다.
+// 버려진(evict, drop) 아이템은 SetOverflow로 지정한 콜백에 전달된다.
+type OverflowPolicy int
+
+const (
+	// Push는 자리가 날 때까지 대기한다(기본값). TryPush는 ErrFull
+	Block OverflowPolicy = iota
+	// Push, TryPush 모두 대기하지 않고 ErrFull
+	Reject
+	// 가장 낮은 우선순위의 아이템을 버리고 새 아이템을 추가한다.
+	// 새 아이템이 가장 낮은 우선순위면(같으면 나중에 입력된 것이 낮다) 새 아이템을 버리고 ErrDropped
+	EvictLowestPriority
+	// 새 아이템을 버리고 ErrDropped(채널의 아이템은 그대로 유지)
+	DropNewest
+)
+
+func (p OverflowPolicy) String() string {
+	switch p {
+	case Block:
+		return "Block"
+	case Reject:
+		return "Reject"
+	case EvictLowestPriority:
+		return "EvictLowestPriority"
+	case DropNewest:
+		return "DropNewest"
+	}
+	return "OverflowPolicy(?)"
+}
+
+// 채널이 가득찼을 때의 정책과 버려진 아이템을 받을 콜백을 지정한다(콜백 nil: 버림)
+// 콜백은 채널 락을 푼 뒤에 push를 호출한 고루틴에서 호출되므로, 채널 메소드를 호출해도 된다.
+//
+//	// 위급한 환자가 오면 가장 경미한 환자를 다른 병원으로 보낸다
+//	transfer := make(chan Item[Patient, Triage], 100)
+//	er.SetOverflow(EvictLowestPriority, DeadLetter(transfer))
+func (c *PriorityChannel[T, P]) SetOverflow(policy OverflowPolicy, onEvict func(Item[T, P])) {
+	c.wLock()
+	defer c.wUnlock()
+	c.overflow = policy
+	c.onEvict = onEvict
+}
+
+// 버려진 아이템을 dead-letter 채널로 보내는 콜백
+// 채널이 가득차면 push를 호출한 고루틴이 대기하므로, 충분한 버퍼를 두거나 계속 읽어야 한다.
+func DeadLetter[T, P any](ch chan<- Item[T, P]) func(Item[T, P]) {
+	return func(item Item[T, P]) {
+		ch <- item
+	}
+}
+
+// 가득찬 채널에 아이템을 추가할 때 정책을 적용한다. 락을 잡은 상태에서 호출
+// 자리가 났으면 nil, 그렇지 않으면 ErrFull, ErrDropped를 반환한다.
+func (c *PriorityChannel[T, P]) overflowed(data T, priority P) error {
+	switch c.overflow {
+	case EvictLowestPriority:
+		if c.q.Len() > 0 {
+			c.age()
+			worst := c.q.Worst()
+
+			// 새 아이템은 seq가 가장 크므로, 우선순위가 같으면 새 아이템이 더 낮다
+			effective := priority
+			if c.aging != nil {
+				effective = c.aging(priority, 0)
+			}
+			if c.q.less(effective, worst.effective) {
+				if err := c.log(walRemove, worst); err != nil {
+					return err
+				}
+				heap.Remove(&c.q, worst.index)
+				c.checkpoint()
+				c.observeEvict(worst)
+				c.evict(worst.item(c.clock.Now()))
+				return nil
+			}
+		}
+		fallthrough
+	case DropNewest:
+		now := c.clock.Now()
+		c.evict(Item[T, P]{Data: data, Priority: priority, Effective: priority, EnqueuedAt: now})
+		return ErrDropped
+	}
+	return ErrFull
+}
+
+// 버려진 아이템을 콜백에 전달한다. 락을 잡은 상태에서 호출
+// 콜백은 wUnlock에서 호출된다.
+func (c *PriorityChannel[T, P]) evict(item Item[T, P]) {
+	c.later(c.onEvict, item)
+}
@@ -0,0 +1,194 @@
+package pqueue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Reject 정책에서는 Push도 대기하지 않는다
+func TestOverflowRejectShouldNotBlockPush(t *testing.T) {
+	assert := assert.New(t)
+
+	pc := NewChannel[string](1)
+	pc.SetOverflow(Reject, nil)
+	assert.NoError(push(pc, "a", 1))
+
+	h, err := pc.Push("b", 0)
+	assert.Nil(h)
+	assert.Equal(ErrFull, err)
+	assert.Equal(1, pc.Count())
+}
+
+// Block 정책(기본값)의 Push는 busy-waiting 없이 자리가 날 때까지 대기한다
+func TestOverflowBlockShouldWaitWithoutPolling(t *testing.T) {
+	assert := assert.New(t)
+
+	pc := NewChannel[string](1)
+	pc.SetTick(time.Hour) // polling 한다면 깨어나지 못한다
+	assert.NoError(push(pc, "a", 1))
+
+	pushed := make(chan error)
+	go func() {
+		_, err := pc.Push("b", 2)
+		pushed <- err
+	}()
+
+	select {
+	case <-pushed:
+		t.Fatal("push should block while the channel is full")
+	case <-time.After(time.Millisecond * 20):
+	}
+
+	data, err := pc.Deque()
+	assert.NoError(err)
+	assert.Equal("a", data)
+
+	select {
+	case err := <-pushed:
+		assert.NoError(err)
+	case <-time.After(time.Second):
+		t.Fatal("push should be woken by pop")
+	}
+
+	// 대기중인 Push는 Close로 깨어나 ErrClosed를 반환한다
+	go func() {
+		_, err := pc.Push("c", 3)
+		pushed <- err
+	}()
+	time.Sleep(time.Millisecond * 20)
+	pc.Close()
+	assert.Equal(ErrClosed, <-pushed)
+}
+
+// 위급한 환자가 오면 가장 경미한 환자를 dead-letter 채널로 보낸다
+func TestOverflowEvictLowestPriorityShouldDisplaceMinorItem(t *testing.T) {
+	assert := assert.New(t)
+
+	transfer := make(chan Item[string, int], 10)
+	pc := NewChannel[string](3)
+	pc.SetOverflow(EvictLowestPriority, DeadLetter(transfer))
+
+	assert.NoError(push(pc, "minor-70", 70))
+	h, err := pc.Push("minor-90", 90)
+	assert.NoError(err)
+	assert.NoError(push(pc, "minor-80", 80))
+
+	// 위급한 환자: 가장 낮은 우선순위(90)를 밀어낸다
+	_, err = pc.Push("critical", 1)
+	assert.NoError(err)
+	evicted := <-transfer
+	assert.Equal("minor-90", evicted.Data)
+	assert.Equal(90, evicted.Priority)
+	assert.False(h.Queued())
+
+	// 새 아이템이 가장 낮은 우선순위면 새 아이템을 버린다
+	h, err = pc.Push("minor-95", 95)
+	assert.Nil(h)
+	assert.Equal(ErrDropped, err)
+	assert.Equal("minor-95", (<-transfer).Data)
+
+	// 우선순위가 같으면 나중에 입력된 새 아이템이 낮다
+	assert.Equal(ErrDropped, pc.Enque("minor-80-late", 80))
+	assert.Equal("minor-80-late", (<-transfer).Data)
+
+	assert.Equal([]string{"critical", "minor-70", "minor-80"}, drainAll(pc))
+	assert.Len(transfer, 0)
+}
+
+// DropNewest 정책은 채널의 아이템을 유지하고 새 아이템을 버린다
+func TestOverflowDropNewestShouldKeepQueuedItems(t *testing.T) {
+	assert := assert.New(t)
+
+	var dropped []string
+	pc := NewChannel[string](2)
+	pc.SetOverflow(DropNewest, func(item Item[string, int]) {
+		// 콜백은 락을 푼 뒤에 호출되므로 채널 메소드를 호출해도 된다
+		assert.Equal(2, pc.Count())
+		dropped = append(dropped, item.Data)
+	})
+
+	assert.NoError(push(pc, "a", 5))
+	assert.NoError(push(pc, "b", 6))
+	_, ok := pc.TryPush("c", 0)
+	assert.False(ok)
+	_, err := pc.Push("d", 1)
+	assert.Equal(ErrDropped, err)
+
+	assert.Equal([]string{"c", "d"}, dropped)
+	assert.Equal([]string{"a", "b"}, drainAll(pc))
+}
+
+// EvictLowestPriority: PushBatch는 배치 전체가 들어갈 자리를 만들거나, 배치 전체를 버린다
+func TestOverflowPolicyEvictLowestPriorityShouldApplyToWholeBatch(t *testing.T) {
+	assert := assert.New(t)
+
+	var evicted []string
+	pc := NewChannel[string](3)
+	m := NewMetrics[int]("", nil)
+	pc.SetObserver(m)
+	pc.SetOverflow(EvictLowestPriority, func(item Item[string, int]) {
+		evicted = append(evicted, item.Data)
+	})
+	for _, p := range []Item[string, int]{{Data: "x", Priority: 1}, {Data: "y", Priority: 8}, {Data: "z", Priority: 9}} {
+		_, err := pc.Push(p.Data, p.Priority)
+		assert.NoError(err)
+	}
+
+	// 가장 낮은 두 아이템을 버리고 배치를 추가한다
+	handles, err := pc.PushBatch([]Item[string, int]{{Data: "a", Priority: 3}, {Data: "b", Priority: 4}})
+	assert.NoError(err)
+	assert.Len(handles, 2)
+	assert.Equal([]string{"z", "y"}, evicted)
+	assert.Equal(uint64(2), m.Evicted())
+
+	// 배치의 아이템끼리 밀어내야 하면(d가 a, b보다 낮다) 배치 전체를 버린다
+	evicted = nil
+	handles, err = pc.PushBatch([]Item[string, int]{{Data: "c", Priority: 2}, {Data: "d", Priority: 9}})
+	assert.Equal(ErrDropped, err)
+	assert.Nil(handles)
+	assert.Equal([]string{"c", "d"}, evicted)
+	assert.Equal(uint64(2), m.Rejected(ErrDropped))
+
+	// cap보다 큰 배치도 버린다
+	evicted = nil
+	_, err = pc.PushBatch([]Item[string, int]{{Data: "e"}, {Data: "f"}, {Data: "g"}, {Data: "h"}})
+	assert.Equal(ErrDropped, err)
+	assert.Equal([]string{"e", "f", "g", "h"}, evicted)
+
+	assert.Equal(3, m.Depth())
+	assert.Equal([]string{"x", "a", "b"}, drainAll(pc))
+}
+
+// DropNewest: PushBatch가 들어가지 않으면 배치 전체를 버린다
+func TestOverflowPolicyDropNewestShouldApplyToWholeBatch(t *testing.T) {
+	assert := assert.New(t)
+
+	var evicted []string
+	pc := NewChannel[string](2)
+	pc.SetOverflow(DropNewest, func(item Item[string, int]) {
+		evicted = append(evicted, item.Data)
+	})
+	assert.NoError(push(pc, "a", 9))
+
+	handles, err := pc.PushBatch([]Item[string, int]{{Data: "b", Priority: 1}, {Data: "c", Priority: 2}})
+	assert.Equal(ErrDropped, err)
+	assert.Nil(handles)
+	assert.Equal([]string{"b", "c"}, evicted)
+	assert.Equal(1, pc.Count())
+
+	handles, err = pc.PushBatch([]Item[string, int]{{Data: "b", Priority: 1}})
+	assert.NoError(err)
+	assert.Len(handles, 1)
+	assert.Equal([]string{"b", "a"}, drainAll(pc))
+}
+
+func TestOverflowPolicyString(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("Block", Block.String())
+	assert.Equal("Reject", Reject.String())
+	assert.Equal("EvictLowestPriority", EvictLowestPriority.String())
+	assert.Equal("DropNewest", DropNewest.String())
+}
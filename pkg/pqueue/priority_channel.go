@@ -0,0 +1,573 @@
+package pqueue
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+
+	"gostudy/pkg/clock"
+	"gostudy/pkg/queue"
+)
+
+// 우선순위 채널 에러
+var (
+	ErrClosed = queue.ErrClosed // 닫힌 채널
+	ErrFull   = queue.ErrFull   // 채널 버퍼 가득참
+	ErrEmpty  = queue.ErrEmpty  // 채널 버퍼 비어있음
+)
+
+// 채널 데이터 래퍼
+type element[T, P any] struct {
+	data     T // 데이터
+	priority P // 우선순위
+}
+
+// 채널에서 꺼낸 아이템 정보
+type Item[T, P any] struct {
+	Data       T             // 데이터
+	Priority   P             // 입력시 우선순위
+	Effective  P             // 꺼낼 때의 유효 우선순위(aging 적용)
+	EnqueuedAt time.Time     // 입력 시간
+	Waited     time.Duration // 대기 시간
+}
+
+// 우선순위로 사용할 수 있는 순서 비교 가능 타입
+type ordered interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64 | ~string
+}
+
+// 오름차순 비교 함수(작은 값이 우선: min-first)
+func Less[P ordered](a, b P) bool {
+	return a < b
+}
+
+// 내림차순 비교 함수(큰 값이 우선: max-first)
+func Greater[P ordered](a, b P) bool {
+	return a > b
+}
+
+// 우선순위 채널
+// 우선순위 P는 less 함수로 비교하며, 우선순위가 같으면 입력 순서(FIFO)를 유지한다.
+type PriorityChannel[T, P any] struct {
+	q      priorityHeap[T, P] // 채널 아이템 버퍼(heap)
+	seq    uint64             // 입력 순서 카운터
+	l      sync.RWMutex       // 채널 락
+	cap    int                // 채널 버퍼 크기
+	closed bool               // 채널 종료
+	clock  clock.Clock        // 시계(기본값 clock.Real)
+
+	aging      AgingFunc[P]  // 대기 시간에 따른 우선순위 보정(nil: 사용안함)
+	resolution time.Duration // aging 재계산 주기
+	agedAt     time.Time     // 마지막 aging 재계산 시간
+
+	wal      *wal[T, P]  // WAL 영속화(nil: 사용안함)
+	observer Observer[P] // 상태 변화 관찰(nil: 사용안함)
+
+	overflow OverflowPolicy   // 가득찼을 때의 정책
+	onEvict  func(Item[T, P]) // 버려진 아이템 콜백(nil: 버림)
+
+	expired  func(P) bool     // 만료 여부(nil: 사용안함)
+	onExpire func(Item[T, P]) // 만료된 아이템 콜백(nil: 버림)
+
+	pending []func() // 락을 푼 뒤 호출할 콜백
+
+	notify chan struct{} // 상태 변경(push, pop, close) 알림
+	done   chan struct{} // Close, CloseNow 호출시 close
+	abort  chan struct{} // CloseNow 호출시 close
+}
+
+// 채널 생성(int 우선순위, 작은 값이 우선)
+func NewChannel[T any](cap int) *PriorityChannel[T, int] {
+	return NewChannelFunc[T](cap, Less[int])
+}
+
+// 순서 비교 가능한 타입을 우선순위로 사용하는 채널 생성(작은 값이 우선)
+// 예: NewOrderedChannel[Job, float64](100)
+func NewOrderedChannel[T any, P ordered](cap int) *PriorityChannel[T, P] {
+	return NewChannelFunc[T](cap, Less[P])
+}
+
+// 우선순위 비교 함수를 지정하여 채널 생성
+// less(a, b)는 a가 b보다 먼저 처리되어야 하면 true를 반환한다.
+// 예: NewChannelFunc[Job](100, Greater[int]) // 큰 값이 우선(max-first)
+func NewChannelFunc[T, P any](cap int, less func(a, b P) bool) *PriorityChannel[T, P] {
+	return &PriorityChannel[T, P]{
+		q:     priorityHeap[T, P]{less: less},
+		cap:   cap,
+		clock: clock.Real,
+		done:  make(chan struct{}),
+		abort: make(chan struct{}),
+	}
+}
+
+/*
+*******************************************************************************
+sync.Mutex 등 copylock이 걸린 오브젝트를 가지는 구조체의 리시버는 반드시 포인터 타입
+엄밀히는 sync.Locker interface를 구현하는 오브젝트. (compile warning)
+********************************************************************************
+*/
+//
+// Pop은 더 이상 busy-waiting 하지 않고 상태 변경 알림(changed)을 기다리므로 tick은 사용하지 않는다.
+// 이전 코드와의 호환을 위해 남겨둔다.
+func (c *PriorityChannel[T, P]) SetTick(tick time.Duration) {
+}
+
+// 채널이 사용할 시계를 지정한다(입력 시간, 대기 시간, aging, 만료)
+// 테스트에서 clock.Fake를 지정하면 실제로 기다리지 않고 시간을 진행시킬 수 있다.
+// 채널을 사용하기 전에 지정한다.
+func (c *PriorityChannel[T, P]) SetClock(clk clock.Clock) {
+	c.wLock()
+	defer c.wUnlock()
+	c.clock = clk
+	c.agedAt = time.Time{} // 이전 시계 기준의 aging 재계산 시간
+}
+
+// 버퍼 크기(cap)를 바꾼다.
+// 이미 입력된 아이템은 cap을 넘어도 유지되며, 늘어난 자리만큼 대기중인 push가 깨어난다.
+func (c *PriorityChannel[T, P]) SetCap(cap int) {
+	c.wLock()
+	defer c.wUnlock()
+	c.cap = cap
+	c.broadcast()
+}
+
+// 채널 종료
+// Go 채널과 마찬가지로 더 이상 push 할 수 없지만, 남아있는 아이템은 pop 할 수 있다.
+// 여러번 호출해도 안전하다.
+func (c *PriorityChannel[T, P]) Close() {
+	c.wLock()
+	defer c.wUnlock()
+	if !c.closed {
+		c.close()
+		c.observeClose()
+	}
+}
+
+// 채널을 닫고 대기중인 고루틴을 깨운다. 락을 잡은 상태에서 호출
+func (c *PriorityChannel[T, P]) close() {
+	if c.closed {
+		return
+	}
+	c.closed = true
+	close(c.done)
+	c.broadcast()
+}
+
+// 채널을 즉시 종료하고, 남아있는 아이템을 모두 버린다.
+// 버려진 아이템의 수를 반환한다.
+// WAL에 기록하지 못하면 채널은 닫히지만 아이템은 버리지 않고(복구할 상태와 같게 유지) 에러를 반환한다.
+func (c *PriorityChannel[T, P]) CloseNow() (int, error) {
+	c.wLock()
+	defer c.wUnlock()
+	notify := !c.closed || c.q.Len() > 0
+	c.close()
+	select {
+	case <-c.abort:
+	default:
+		close(c.abort)
+	}
+	discarded := c.q.Len()
+	if err := c.log(walClear, nil); err != nil {
+		return 0, err
+	}
+	c.q.Clear()
+	c.checkpoint()
+	if notify {
+		c.observeClose()
+	}
+	return discarded, nil
+}
+
+// 채널이 닫혔나?
+func (c *PriorityChannel[T, P]) Closed() bool {
+	c.rLock()
+	defer c.rUnlock()
+	return c.closed
+}
+
+// 상태 변경 알림 채널을 반환한다. 락을 잡은 상태에서 호출
+// 반환된 채널은 다음 push, pop, close 시점에 close 된다.
+func (c *PriorityChannel[T, P]) changed() <-chan struct{} {
+	if c.notify == nil {
+		c.notify = make(chan struct{})
+	}
+	return c.notify
+}
+
+// 상태 변경을 기다리는 고루틴을 모두 깨운다. 락을 잡은 상태에서 호출
+func (c *PriorityChannel[T, P]) broadcast() {
+	if c.notify != nil {
+		close(c.notify)
+		c.notify = nil
+	}
+}
+
+// 쓰기 락
+func (c *PriorityChannel[T, P]) wLock() {
+	c.l.Lock()
+}
+
+// 쓰기 언락
+// 락을 잡은 동안 버려진(evict, expire) 아이템이 있으면, 락을 푼 뒤에 콜백에 전달한다.
+func (c *PriorityChannel[T, P]) wUnlock() {
+	pending := c.pending
+	c.pending = nil
+	c.l.Unlock()
+	for _, callback := range pending {
+		callback()
+	}
+}
+
+// 락을 푼 뒤에 item으로 callback을 호출한다. 락을 잡은 상태에서 호출
+func (c *PriorityChannel[T, P]) later(callback func(Item[T, P]), item Item[T, P]) {
+	if callback != nil {
+		c.pending = append(c.pending, func() { callback(item) })
+	}
+}
+
+// 읽기 락
+func (c *PriorityChannel[T, P]) rLock() {
+	c.l.RLock()
+}
+
+// 읽기 언락
+func (c *PriorityChannel[T, P]) rUnlock() {
+	c.l.RUnlock()
+}
+
+//////////////////////////////////////////////////////////////////////////////////
+// 읽기락/읽기언락은 여기서는 굳이 필요없지만, read/write lock에 대해서 설명하기 위함
+//////////////////////////////////////////////////////////////////////////////////
+
+// 채널의 현재 원소 갯수
+func (c *PriorityChannel[T, P]) Count() int {
+	c.rLock()
+	defer c.rUnlock()
+	return c.q.Len()
+}
+
+// 채널에서 데이터를 하나 꺼낸다
+func (c *PriorityChannel[T, P]) TryPop() (item element[T, P], ok bool) {
+	item, err := c.tryPop()
+	return item, err == nil
+}
+
+// 채널에서 데이터를 하나 꺼낸다
+// 비어있으면 ErrEmpty, 비어있고 닫혔으면 ErrClosed
+func (c *PriorityChannel[T, P]) tryPop() (item element[T, P], err error) {
+	c.wLock()
+	defer c.wUnlock()
+
+	x, err := c.pop()
+	if err != nil {
+		return item, err
+	}
+	return x.element, nil
+}
+
+// 힙에서 아이템을 하나 꺼낸다. 락을 잡은 상태에서 호출
+func (c *PriorityChannel[T, P]) pop() (*heapItem[T, P], error) {
+	// 만료된 아이템은 꺼내지 않고 콜백에 전달한다
+	if err := c.expire(); err != nil {
+		return nil, err
+	}
+
+	// 닫힌 채널이라도 남은 아이템은 꺼낼 수 있다(drain)
+	if c.q.Len() > 0 {
+		c.age()
+		if err := c.log(walPop, c.q.Peek()); err != nil {
+			return nil, err
+		}
+		item := heap.Pop(&c.q).(*heapItem[T, P])
+		c.checkpoint()
+		c.observePop(item)
+		c.broadcast()
+		return item, nil
+	}
+
+	if c.closed {
+		return nil, ErrClosed
+	}
+	return nil, ErrEmpty
+}
+
+// 채널에서 데이터를 하나 꺼낸다(데이터 있을때 까지 대기)
+// ctx가 종료되면 ctx.Err()를 반환한다.
+func (c *PriorityChannel[T, P]) popContext(ctx context.Context) (*heapItem[T, P], error) {
+	for {
+		c.wLock()
+		item, err := c.pop()
+		if err != ErrEmpty {
+			c.wUnlock()
+			return item, err
+		}
+		changed := c.changed()
+		c.wUnlock()
+
+		// wait for data
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// 꺼낸 아이템을 원래 순서(seq) 그대로 되돌린다.
+// CloseNow로 버려진 채널에는 되돌리지 않는다(ErrClosed).
+// 그 사이에 채널이 가득찼거나(ErrFull) WAL 기록에 실패하면 되돌리지 않고 오버플로 콜백(SetOverflow)에 전달한다.
+func (c *PriorityChannel[T, P]) restore(item *heapItem[T, P]) error {
+	c.wLock()
+	defer c.wUnlock()
+
+	select {
+	case <-c.abort:
+		return ErrClosed
+	default:
+	}
+
+	if c.q.Len() >= c.cap {
+		c.evict(item.item(c.clock.Now()))
+		return ErrFull
+	}
+	if err := c.log(walPush, item); err != nil {
+		c.evict(item.item(c.clock.Now()))
+		return err
+	}
+	heap.Push(&c.q, item)
+	c.checkpoint()
+	c.observePush(item)
+	c.broadcast()
+	return nil
+}
+
+// 채널에 넣지 못한 데이터를 오버플로 콜백(SetOverflow)에 전달한다.
+func (c *PriorityChannel[T, P]) reject(data T, priority P) {
+	c.wLock()
+	defer c.wUnlock()
+	c.evict(Item[T, P]{Data: data, Priority: priority, Effective: priority, EnqueuedAt: c.clock.Now()})
+}
+
+// 채널에 데이터를 추가
+// 입력된 아이템의 우선순위 변경, 삭제 등을 위한 핸들을 반환한다.
+func (c *PriorityChannel[T, P]) TryPush(data T, priority P) (*Handle[T, P], bool) {
+	h, err := c.tryPush(data, priority)
+	return h, err == nil
+}
+
+// 채널에 데이터를 추가
+// 닫혔으면 ErrClosed, 가득찼으면 ErrFull(혹은 오버플로 정책에 따라 ErrDropped)
+func (c *PriorityChannel[T, P]) tryPush(data T, priority P) (*Handle[T, P], error) {
+	c.wLock()
+	defer c.wUnlock()
+	h, err := c.push(data, priority)
+	c.observeReject(priority, err)
+	return h, err
+}
+
+// 힙에 아이템을 추가한다. 락을 잡은 상태에서 호출
+func (c *PriorityChannel[T, P]) push(data T, priority P) (*Handle[T, P], error) {
+	// 채널이 닫혔으면 실패 처리
+	if c.closed {
+		return nil, ErrClosed
+	}
+
+	// cap 초과 검사(오버플로 정책 적용)
+	if c.q.Len() >= c.cap {
+		if err := c.overflowed(data, priority); err != nil {
+			return nil, err
+		}
+	}
+
+	// 새로운 아이템을 힙에 추가: O(logN)
+	item := c.newItem(data, priority)
+	if err := c.log(walPush, item); err != nil {
+		return nil, err
+	}
+	return c.insert(item), nil
+}
+
+// 새 아이템을 만든다. 락을 잡은 상태에서 호출
+// 같은 우선순위의 아이템은 seq로 입력 순서를 유지한다.
+func (c *PriorityChannel[T, P]) newItem(data T, priority P) *heapItem[T, P] {
+	c.seq++
+	item := &heapItem[T, P]{
+		element:    element[T, P]{data: data, priority: priority},
+		effective:  priority,
+		enqueuedAt: c.clock.Now(),
+		seq:        c.seq,
+	}
+	if c.aging != nil {
+		item.effective = c.aging(priority, 0)
+	}
+	return item
+}
+
+// WAL에 기록한 아이템을 힙에 추가하고 핸들을 반환한다. 락을 잡은 상태에서 호출
+func (c *PriorityChannel[T, P]) insert(item *heapItem[T, P]) *Handle[T, P] {
+	heap.Push(&c.q, item)
+	c.checkpoint()
+	c.observePush(item)
+	c.broadcast()
+	return &Handle[T, P]{c: c, item: item}
+}
+
+// 채널에 데이터를 추가(입력 완료까지 대기)
+// ctx가 종료되면 ctx.Err()를 반환한다.
+// 가득찬 경우는 거절이 아니라 대기이므로 observer에 알리지 않는다.
+func (c *PriorityChannel[T, P]) pushContext(ctx context.Context, data T, priority P) (*Handle[T, P], error) {
+	for {
+		c.wLock()
+		h, err := c.push(data, priority)
+		if err != ErrFull {
+			c.observeReject(priority, err)
+			c.wUnlock()
+			return h, err
+		}
+		changed := c.changed()
+		c.wUnlock()
+
+		// wait for channel ready
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// 채널에 데이터를 푸시
+// 입력된 아이템의 우선순위 변경, 삭제 등을 위한 핸들을 반환한다.
+// 가득찼을 때는 오버플로 정책(SetOverflow)을 따른다. 기본값(Block)은 자리가 날 때까지 대기한다.
+// ch <- data
+func (c *PriorityChannel[T, P]) Push(data T, priority P) (*Handle[T, P], error) {
+	c.rLock()
+	policy := c.overflow
+	c.rUnlock()
+
+	if policy == Block {
+		return c.pushContext(context.Background(), data, priority)
+	}
+	return c.tryPush(data, priority)
+}
+
+// 채널에서 데이터를 팝(데이터 있을때 까지 대기)
+// data <- ch
+func (c *PriorityChannel[T, P]) Pop() (data T, err error) {
+	data, _, err = c.PopWithPriority()
+	if err != nil {
+		return data, err
+	}
+	return data, nil
+}
+
+// 채널에서 아이템을 대기 시간 등의 정보와 함께 팝(데이터 있을때 까지 대기)
+// 비어있으면 push, close 알림(changed)을 기다린다. 닫히고 남은 아이템이 없으면 ErrClosed
+func (c *PriorityChannel[T, P]) PopItem() (item Item[T, P], err error) {
+	x, err := c.popContext(context.Background())
+	if err != nil {
+		return item, err
+	}
+	return x.item(c.clock.Now()), nil
+}
+
+// now 시점에 채널에서 꺼낸 아이템 정보
+func (x *heapItem[T, P]) item(now time.Time) Item[T, P] {
+	return Item[T, P]{
+		Data:       x.data,
+		Priority:   x.priority,
+		Effective:  x.effective,
+		EnqueuedAt: x.enqueuedAt,
+		Waited:     now.Sub(x.enqueuedAt),
+	}
+}
+
+// 채널에서 데이터를 우선순위와 함께 팝(데이터 있을때 까지 대기)
+func (c *PriorityChannel[T, P]) PopWithPriority() (data T, priority P, err error) {
+	item, err := c.PopItem()
+	return item.Data, item.Priority, err
+}
+
+// 채널에 데이터를 추가(대기하지 않음)
+// 실패시 ErrClosed, ErrFull
+func (c *PriorityChannel[T, P]) Enque(data T, priority P) error {
+	_, err := c.tryPush(data, priority)
+	return err
+}
+
+// 채널에서 데이터를 꺼낸다(대기하지 않음)
+// 실패시 ErrEmpty, ErrClosed
+func (c *PriorityChannel[T, P]) Deque() (data T, err error) {
+	item, err := c.tryPop()
+	return item.data, err
+}
+
+// 우선순위 순으로 데이터를 출력하는 Go 채널을 반환한다.
+// select, for range 구문에서 다른 채널(timer, ctx.Done() 등)과 함께 사용할 수 있다.
+//
+// 채널이 닫히고 남은 아이템을 모두 출력하면(Close), 혹은 즉시 종료되면(CloseNow),
+// 혹은 ctx가 종료되면 출력 채널도 닫힌다.
+// ctx 종료로 전달하지 못한 아이템은 원래 순서 그대로 우선순위 채널에 되돌린다.
+// 그 사이에 채널이 가득차서 되돌리지 못한 아이템은 오버플로 콜백(SetOverflow)에 전달한다.
+func (c *PriorityChannel[T, P]) Out(ctx context.Context) <-chan T {
+	out := make(chan T)
+
+	// pump goroutine
+	go func() {
+		defer close(out)
+		for {
+			item, err := c.popContext(ctx)
+			if err != nil {
+				return
+			}
+
+			select {
+			case out <- item.data:
+			case <-c.abort:
+				return
+			case <-ctx.Done():
+				c.restore(item)
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// priority 우선순위로 데이터를 입력받는 Go 채널을 반환한다.
+//
+// 채널이 닫히거나(Close, CloseNow) ctx가 종료되면 입력 고루틴이 종료되고,
+// 이후로는 입력 채널을 읽지 않는다. 따라서 보내는 쪽에서는 select로 ctx.Done() 등을
+// 함께 검사해야 한다. 입력 채널을 close 해도 입력 고루틴이 종료된다.
+//
+// 입력 고루틴이 받은 데이터를 추가하지 못하고 종료되면(가득찬 채널에서 대기중에 ctx 종료 등)
+// 그 데이터는 오버플로 콜백(SetOverflow)에 전달한다. 콜백이 없으면 버려진다.
+func (c *PriorityChannel[T, P]) In(ctx context.Context, priority P) chan<- T {
+	in := make(chan T)
+
+	go func() {
+		for {
+			select {
+			case data, ok := <-in:
+				if !ok {
+					return
+				}
+				// 오버플로 정책으로 버려진 아이템은 무시하고 계속 입력받는다
+				if _, err := c.pushContext(ctx, data, priority); err != nil && err != ErrDropped {
+					c.reject(data, priority)
+					return
+				}
+			case <-c.done:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return in
+}
@@ -0,0 +1,620 @@
+package pqueue
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gostudy/pkg/rng"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 아이템을 push 하고 에러만 반환한다(핸들은 사용하지 않음)
+func push[T, P any](pc *PriorityChannel[T, P], data T, priority P) error {
+	_, err := pc.Push(data, priority)
+	return err
+}
+
+// 우선순위 채널이 (1)우선순위 및 (2)입력순서대로 출력하는지 테스트한다.
+func TestPriorityChannelShouldPopOrderedByPriorityAndInputOrder(t *testing.T) {
+	assert := assert.New(t)
+	repeats := 10000
+	pc := NewChannel[int](repeats)
+
+	// push to channel
+	for i := 0; i < repeats; i++ {
+		// input order, random priority
+		pc.Push(i, rng.NextInRange(math.MinInt8, math.MaxInt8))
+	}
+	assert.Equal(repeats, pc.Count())
+
+	// pop from channel
+	items := []element[int, int]{}
+	for i := 0; i < repeats; i++ {
+		data, priority, err := pc.PopWithPriority()
+		if err != nil {
+			panic(err)
+		}
+		items = append(items, element[int, int]{data, priority})
+	}
+	assert.Zero(pc.Count())
+
+	// assert items are ordered by priority and input order
+	prev := element[int, int]{data: -1, priority: math.MinInt8 - 1}
+	for _, item := range items {
+		// 우선순위 기준으로 오름차순(minHeap)
+		assert.GreaterOrEqual(item.priority, prev.priority)
+		// 우선순위가 동일한 경우 입력 순서가 유지되는가?
+		if item.priority == prev.priority {
+			assert.Greater(item.data, prev.data)
+		}
+		prev = item
+	}
+	/* OUTPUT
+	D:\gitworks\go-study\priority-queue>go test -v -run TestPriorityChannelShouldPopOrderedByPriorityAndInputOrder
+	=== RUN   TestPriorityChannelShouldPopOrderedByPriorityAndInputOrder
+	--- PASS: TestPriorityChannelShouldPopOrderedByPriorityAndInputOrder (0.02s)
+	PASS
+	ok      gostudy/priority-queue  0.051s
+	*/
+}
+
+// 우선순위 채널이 concurrent-safe 한지 테스트한다
+func TestPriorityChannelIsConcurrentSafe(t *testing.T) {
+
+	// 우선순위 채널
+	pc := NewChannel[time.Time](1000)
+
+	wg := sync.WaitGroup{}
+	workers := runtime.NumCPU() * 2 // concurrent-safe 검출을 쉽게하기 위함
+	runtime.GOMAXPROCS(workers)
+
+	// 고루틴마다 push/pop 하는 횟수(실제 시간 대신 횟수로 제한)
+	// push와 pop의 총 횟수가 같으므로 대기하는 Pop도 모두 끝난다.
+	const ops = 10000
+
+	// pushed, poped count
+	pushed, poped := uint64(0), uint64(0)
+
+	// channel push goroutine
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func(x int) {
+			defer wg.Done()
+			for n := 0; n < ops; n++ {
+				pc.Push(epoch.Add(time.Duration(n)), rng.Next[int]())
+				atomic.AddUint64(&pushed, 1)
+			}
+		}(i)
+	}
+
+	// channel pop goroutine
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func(x int) {
+			defer wg.Done()
+			for n := 0; n < ops; n++ {
+				pc.Pop()
+				atomic.AddUint64(&poped, 1)
+			}
+		}(i)
+	}
+
+	// wait for all goroutine completed
+	wg.Wait()
+
+	// print push, pop action count
+	t.Logf("push: %d, pop: %d", pushed, poped)
+	assert.Equal(t, uint64(workers*ops), pushed)
+	assert.Equal(t, pushed, poped)
+	assert.Zero(t, pc.Count())
+
+	/* OUTPUT
+	D:\gitworks\go-study\priority-queue>go test -v -run TestPriorityChannelIsConcurrentSafe
+	=== RUN   TestPriorityChannelIsConcurrentSafe
+		priority_queue_test.go:101: push: 18060425, pop: 18060420
+	--- PASS: TestPriorityChannelIsConcurrentSafe (10.00s)
+	PASS
+	ok      gostudy/priority-queue  10.038s
+	*/
+}
+
+// 비교 함수로 큰 값 우선(max-first) 채널을 만들 수 있다
+func TestPriorityChannelFuncShouldPopMaxFirst(t *testing.T) {
+	assert := assert.New(t)
+	pc := NewChannelFunc[string](10, Greater[int])
+
+	assert.NoError(push(pc, "a", 1))
+	assert.NoError(push(pc, "b", 3))
+	assert.NoError(push(pc, "c", 2))
+	assert.NoError(push(pc, "d", 3))
+
+	for _, expected := range []string{"b", "d", "c", "a"} {
+		data, err := pc.Pop()
+		assert.NoError(err)
+		assert.Equal(expected, data)
+	}
+}
+
+// 실수(float) 우선순위 채널
+func TestOrderedChannelShouldPopByFloatScore(t *testing.T) {
+	assert := assert.New(t)
+	pc := NewOrderedChannel[string, float64](10)
+
+	assert.NoError(push(pc, "c", 0.3))
+	assert.NoError(push(pc, "a", -1.5))
+	assert.NoError(push(pc, "b", 0.25))
+
+	for _, expected := range []string{"a", "b", "c"} {
+		data, err := pc.Pop()
+		assert.NoError(err)
+		assert.Equal(expected, data)
+	}
+}
+
+// 복합 우선순위(심각도, 도착시간)에서도 같은 우선순위는 입력 순서를 유지한다
+func TestPriorityChannelFuncShouldKeepInputOrderOnCompositePriority(t *testing.T) {
+	assert := assert.New(t)
+
+	type severity struct {
+		level     int
+		arrivedAt int
+	}
+	pc := NewChannelFunc[int](1000, func(a, b severity) bool {
+		if a.level != b.level {
+			return a.level > b.level // 심각도 높은 순
+		}
+		return a.arrivedAt < b.arrivedAt // 먼저 도착한 순
+	})
+
+	for i := 0; i < 1000; i++ {
+		assert.NoError(push(pc, i, severity{level: rng.NextInRange(0, 5), arrivedAt: rng.NextInRange(0, 10)}))
+	}
+
+	prev := element[int, severity]{data: -1, priority: severity{level: math.MaxInt}}
+	for pc.Count() > 0 {
+		data, priority, err := pc.PopWithPriority()
+		assert.NoError(err)
+		assert.LessOrEqual(priority.level, prev.priority.level)
+		if priority.level == prev.priority.level {
+			assert.GreaterOrEqual(priority.arrivedAt, prev.priority.arrivedAt)
+			if priority.arrivedAt == prev.priority.arrivedAt {
+				assert.Greater(data, prev.data)
+			}
+		}
+		prev = element[int, severity]{data: data, priority: priority}
+	}
+}
+
+// 닫힌 채널에서도 남은 아이템은 우선순위 순으로 모두 꺼낼 수 있어야 한다(drain)
+func TestPriorityChannelCloseShouldDrainRemainingItems(t *testing.T) {
+	assert := assert.New(t)
+	pc := NewChannel[string](10)
+
+	assert.NoError(push(pc, "low", 3))
+	assert.NoError(push(pc, "high", 1))
+	assert.NoError(push(pc, "mid", 2))
+
+	pc.Close()
+	pc.Close() // 여러번 닫아도 안전
+	assert.True(pc.Closed())
+
+	// 닫힌 채널에는 push 할 수 없다
+	assert.ErrorIs(push(pc, "late", 0), ErrClosed)
+	assert.ErrorIs(pc.Enque("late", 0), ErrClosed)
+	_, ok := pc.TryPush("late", 0)
+	assert.False(ok)
+
+	// 남은 아이템은 꺼낼 수 있다
+	for _, expected := range []string{"high", "mid", "low"} {
+		data, err := pc.Pop()
+		assert.NoError(err)
+		assert.Equal(expected, data)
+	}
+
+	// 모두 꺼낸 뒤에는 ErrClosed
+	_, err := pc.Pop()
+	assert.ErrorIs(err, ErrClosed)
+	_, err = pc.Deque()
+	assert.ErrorIs(err, ErrClosed)
+}
+
+// CloseNow는 남아있는 아이템을 즉시 버린다
+func TestPriorityChannelCloseNowShouldDiscardRemainingItems(t *testing.T) {
+	assert := assert.New(t)
+	pc := NewChannel[int](10)
+
+	for i := 0; i < 5; i++ {
+		assert.NoError(pc.Enque(i, i))
+	}
+
+	n, err := pc.CloseNow()
+	assert.NoError(err)
+	assert.Equal(5, n)
+	n, err = pc.CloseNow()
+	assert.NoError(err)
+	assert.Zero(n)
+	assert.Zero(pc.Count())
+
+	_, err = pc.Pop()
+	assert.ErrorIs(err, ErrClosed)
+}
+
+// Enque, Deque는 대기하지 않고 ErrFull, ErrEmpty를 반환한다
+func TestPriorityChannelShouldReturnSentinelErrors(t *testing.T) {
+	assert := assert.New(t)
+	pc := NewChannel[int](1)
+
+	_, err := pc.Deque()
+	assert.ErrorIs(err, ErrEmpty)
+
+	assert.NoError(pc.Enque(1, 1))
+	assert.ErrorIs(pc.Enque(2, 2), ErrFull)
+}
+
+// 비어있는 채널의 Pop은 busy-waiting 없이 push 될 때까지 대기한다
+func TestPriorityChannelPopShouldWaitWithoutPolling(t *testing.T) {
+	assert := assert.New(t)
+
+	pc := NewChannel[string](1)
+	pc.SetTick(time.Hour) // polling 한다면 깨어나지 못한다
+
+	popped := make(chan string)
+	go func() {
+		data, err := pc.Pop()
+		assert.NoError(err)
+		popped <- data
+	}()
+
+	select {
+	case <-popped:
+		t.Fatal("pop should block while the channel is empty")
+	case <-time.After(time.Millisecond * 20):
+	}
+
+	assert.NoError(push(pc, "a", 1))
+	select {
+	case data := <-popped:
+		assert.Equal("a", data)
+	case <-time.After(time.Second):
+		t.Fatal("pop should be woken by push")
+	}
+}
+
+// 대기중인 Pop, Push는 Close 이후 ErrClosed를 반환하고 종료해야 한다
+// go test -race 로 실행하여 data race가 없는지 확인
+func TestPriorityChannelCloseShouldWakeUpWaiters(t *testing.T) {
+	assert := assert.New(t)
+	pc := NewChannel[int](1)
+	pc.SetTick(time.Millisecond)
+	assert.NoError(push(pc, 0, 0))
+
+	wg := sync.WaitGroup{}
+	pushErrs := make(chan error, 4)
+	popErrs := make(chan error, 4)
+
+	// 가득 찬 채널에 push 대기
+	wg.Add(4)
+	for i := 0; i < 4; i++ {
+		go func() {
+			defer wg.Done()
+			pushErrs <- push(pc, 1, 1)
+		}()
+	}
+
+	// pop 대기(닫힐 때까지 계속 꺼낸다)
+	wg.Add(4)
+	for i := 0; i < 4; i++ {
+		go func() {
+			defer wg.Done()
+			for {
+				if _, err := pc.Pop(); err != nil {
+					popErrs <- err
+					return
+				}
+			}
+		}()
+	}
+
+	// 동시에 여러번 닫는다
+	time.Sleep(time.Millisecond * 10)
+	for i := 0; i < 4; i++ {
+		go pc.Close()
+	}
+	wg.Wait()
+	close(pushErrs)
+	close(popErrs)
+
+	// push 대기는 성공했거나 ErrClosed
+	for err := range pushErrs {
+		if err != nil {
+			assert.ErrorIs(err, ErrClosed)
+		}
+	}
+	// pop 대기는 모두 drain 후 ErrClosed
+	for err := range popErrs {
+		assert.ErrorIs(err, ErrClosed)
+	}
+	assert.Zero(pc.Count())
+}
+
+// Out 채널은 우선순위 순으로 출력하고, Close 후 남은 아이템을 모두 출력하면 닫힌다
+func TestPriorityChannelOutShouldRangeInPriorityOrder(t *testing.T) {
+	assert := assert.New(t)
+	pc := NewChannel[int](100)
+
+	for i := 0; i < 100; i++ {
+		assert.NoError(push(pc, i, i%10))
+	}
+	pc.Close()
+
+	prev := element[int, int]{data: -1, priority: -1}
+	count := 0
+	for data := range pc.Out(context.Background()) {
+		item := element[int, int]{data: data, priority: data % 10}
+		assert.GreaterOrEqual(item.priority, prev.priority)
+		if item.priority == prev.priority {
+			assert.Greater(item.data, prev.data)
+		}
+		prev = item
+		count++
+	}
+	assert.Equal(100, count)
+}
+
+// Out 채널은 select 구문에서 timer, ctx.Done() 등과 함께 사용할 수 있다
+func TestPriorityChannelOutShouldWorkWithSelect(t *testing.T) {
+	assert := assert.New(t)
+	pc := NewChannel[string](10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	out := pc.Out(ctx)
+	in := pc.In(ctx, 1)
+
+	// 아직 데이터가 없다
+	select {
+	case data := <-out:
+		assert.Fail("unexpected data", data)
+	case <-time.After(time.Millisecond * 10):
+	}
+
+	in <- "hello"
+	select {
+	case data := <-out:
+		assert.Equal("hello", data)
+	case <-time.After(time.Second):
+		assert.Fail("timeout")
+	}
+}
+
+// ctx가 종료되면 Out 채널이 닫히고, 전달하지 못한 아이템은 순서 그대로 되돌린다
+func TestPriorityChannelOutShouldRestoreUndeliveredItemOnCancel(t *testing.T) {
+	assert := assert.New(t)
+	pc := NewChannel[int](10)
+	for i := 0; i < 3; i++ {
+		assert.NoError(push(pc, i, 0))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	out := pc.Out(ctx)
+	assert.Equal(0, <-out)
+
+	cancel()
+	for range out {
+		// 취소 직전에 전달된 아이템이 있을 수 있다
+	}
+
+	// 되돌린 아이템은 입력 순서를 유지해야 한다
+	prev := -1
+	for pc.Count() > 0 {
+		data, err := pc.Deque()
+		assert.NoError(err)
+		assert.Greater(data, prev)
+		prev = data
+	}
+	assert.Equal(2, prev)
+}
+
+// 되돌릴 자리가 없으면 전달하지 못한 아이템을 오버플로 콜백에 전달한다
+func TestPriorityChannelOutShouldEvictUndeliveredItemWhenFull(t *testing.T) {
+	assert := assert.New(t)
+	pc := NewChannel[string](1)
+	evicted := make(chan Item[string, int], 1)
+	pc.SetOverflow(Block, DeadLetter(evicted))
+	assert.NoError(push(pc, "a", 0))
+
+	// 출력 대기중인 "a"를 꺼낸 사이에 "b"로 채운다
+	ctx, cancel := context.WithCancel(context.Background())
+	out := pc.Out(ctx)
+	for pc.Count() > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	assert.NoError(push(pc, "b", 0))
+	cancel()
+
+	select {
+	case item := <-evicted:
+		assert.Equal("a", item.Data)
+	case <-time.After(time.Second):
+		t.Fatal("undelivered item should be evicted")
+	}
+	for range out {
+	}
+
+	data, err := pc.Deque()
+	assert.NoError(err)
+	assert.Equal("b", data)
+}
+
+// 가득찬 채널에 추가하려고 대기중에 ctx가 종료되면, 받은 데이터를 오버플로 콜백에 전달한다
+func TestPriorityChannelInShouldEvictPendingItemOnCancel(t *testing.T) {
+	assert := assert.New(t)
+	pc := NewChannel[string](1)
+	evicted := make(chan Item[string, int], 1)
+	pc.SetOverflow(Block, DeadLetter(evicted))
+	assert.NoError(push(pc, "a", 0))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	pc.In(ctx, 5) <- "b"
+	cancel()
+
+	select {
+	case item := <-evicted:
+		assert.Equal("b", item.Data)
+		assert.Equal(5, item.Priority)
+	case <-time.After(time.Second):
+		t.Fatal("pending item should be evicted")
+	}
+	assert.Equal(1, pc.Count())
+}
+
+// In 채널로 입력한 데이터는 지정한 우선순위로 추가된다
+func TestPriorityChannelInShouldPushWithPriority(t *testing.T) {
+	assert := assert.New(t)
+	pc := NewChannel[string](10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	low, high := pc.In(ctx, 9), pc.In(ctx, 1)
+	low <- "low"
+	high <- "high"
+
+	// In 고루틴이 push를 완료할 때까지 대기
+	for pc.Count() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	data, priority, err := pc.PopWithPriority()
+	assert.NoError(err)
+	assert.Equal("high", data)
+	assert.Equal(1, priority)
+
+	data, priority, err = pc.PopWithPriority()
+	assert.NoError(err)
+	assert.Equal("low", data)
+	assert.Equal(9, priority)
+}
+
+// Close, CloseNow, ctx 종료시 Out, In 고루틴이 모두 종료되어야 한다(goroutine leak)
+func TestPriorityChannelAdaptersShouldNotLeakGoroutines(t *testing.T) {
+	assert := assert.New(t)
+	before := runtime.NumGoroutine()
+
+	// Close: 남은 아이템을 모두 읽으면 종료
+	pc := NewChannel[int](10)
+	ctx := context.Background()
+	out := pc.Out(ctx)
+	pc.In(ctx, 0) <- 1
+	assert.Equal(1, <-out)
+	pc.Close()
+	for range out {
+	}
+
+	// CloseNow: 대기중인 아이템을 버리고 즉시 종료(출력 채널을 읽지 않아도)
+	pc = NewChannel[int](10)
+	for i := 0; i < 5; i++ {
+		assert.NoError(push(pc, i, i))
+	}
+	pc.Out(ctx)
+	pc.In(ctx, 0)
+	time.Sleep(time.Millisecond * 10)
+	pc.CloseNow()
+
+	// ctx 종료: 채널이 열려있어도 종료
+	pc = NewChannel[int](1)
+	cctx, cancel := context.WithCancel(ctx)
+	pc.Out(cctx)
+	pc.In(cctx, 0)
+	assert.NoError(push(pc, 0, 0)) // 출력 대기
+	cancel()
+
+	assertNoGoroutineLeak(t, before)
+}
+
+// 고루틴 수가 before 이하로 돌아오는지 검사한다(최대 1초 대기)
+func assertNoGoroutineLeak(t *testing.T, before int) bool {
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	return assert.LessOrEqual(t, runtime.NumGoroutine(), before, "goroutine leak")
+}
+
+// 큐에 n개의 아이템이 쌓여있는 상태에서 push/pop 비용을 측정한다.
+// heap 기반이므로 n이 커져도 O(logN)으로 완만하게 증가해야 한다.
+func BenchmarkPriorityChannelPushPop(b *testing.B) {
+	for _, n := range []int{10000, 100000, 1000000} {
+		b.Run(fmt.Sprintf("n=%d", n), func(b *testing.B) {
+			pc := NewChannel[int](n + 1)
+			for i := 0; i < n; i++ {
+				pc.TryPush(i, rand.Intn(math.MaxInt16))
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				pc.TryPush(i, rand.Intn(math.MaxInt16))
+				pc.TryPop()
+			}
+		})
+	}
+}
+
+// 빈 큐에 n개의 아이템을 push 한 뒤, 모두 pop 하는 비용을 측정한다.
+func BenchmarkPriorityChannelFillAndDrain(b *testing.B) {
+	for _, n := range []int{10000, 100000, 1000000} {
+		b.Run(fmt.Sprintf("n=%d", n), func(b *testing.B) {
+			priorities := make([]int, n)
+			for i := range priorities {
+				priorities[i] = rand.Intn(math.MaxInt16)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				pc := NewChannel[int](n)
+				for j, priority := range priorities {
+					pc.TryPush(j, priority)
+				}
+				for pc.Count() > 0 {
+					pc.TryPop()
+				}
+			}
+		})
+	}
+}
+
+// SetCap으로 cap을 늘리면 대기중인 push가 깨어난다
+func TestPriorityChannelSetCapShouldWakePushers(t *testing.T) {
+	assert := assert.New(t)
+
+	pc := NewChannel[string](1)
+	assert.NoError(push(pc, "a", 0))
+
+	pushed := make(chan error)
+	go func() {
+		_, err := pc.Push("b", 0)
+		pushed <- err
+	}()
+	select {
+	case <-pushed:
+		t.Fatal("push should block while the channel is full")
+	case <-time.After(time.Millisecond * 20):
+	}
+
+	pc.SetCap(2)
+	assert.NoError(<-pushed)
+	assert.Equal(2, pc.Count())
+
+	// 이미 입력된 아이템은 cap을 넘어도 유지된다
+	pc.SetCap(1)
+	assert.Equal(2, pc.Count())
+	_, ok := pc.TryPush("c", 0)
+	assert.False(ok)
+}
@@ -0,0 +1,98 @@
+package pqueue
+
+import (
+	"container/heap"
+	"time"
+)
+
+// 힙에 저장되는 채널 원소
+type heapItem[T, P any] struct {
+	element[T, P]
+	effective  P         // 유효 우선순위(aging 적용, 미적용시 priority와 같음)
+	enqueuedAt time.Time // 입력 시간
+	seq        uint64    // 입력 순서(같은 우선순위에서 FIFO 보장)
+	index      int       // index in the priority queue (heap)
+}
+
+// priorityHeap implements heap.Interface for heapItem.
+//
+// less 함수 기준으로 가장 앞선(같으면 먼저 입력된) 아이템이 root(index 0)에 위치한다.
+// waitForPriorityQueue와 같은 방식으로 구현하되, seq로 입력 순서를 유지한다.
+type priorityHeap[T, P any] struct {
+	items []*heapItem[T, P]
+	less  func(a, b P) bool // 우선순위 비교 함수(a가 b보다 먼저면 true)
+}
+
+func (pq *priorityHeap[T, P]) Len() int {
+	return len(pq.items)
+}
+
+func (pq *priorityHeap[T, P]) Less(i, j int) bool {
+	return pq.before(pq.items[i], pq.items[j])
+}
+
+// a가 b보다 먼저 꺼내져야 하면 true
+func (pq *priorityHeap[T, P]) before(a, b *heapItem[T, P]) bool {
+	if pq.less(a.effective, b.effective) {
+		return true
+	}
+	if pq.less(b.effective, a.effective) {
+		return false
+	}
+	return a.seq < b.seq
+}
+
+func (pq *priorityHeap[T, P]) Swap(i, j int) {
+	pq.items[i], pq.items[j] = pq.items[j], pq.items[i]
+	pq.items[i].index = i
+	pq.items[j].index = j
+}
+
+// Push adds an item to the heap. Push should not be called directly; instead,
+// use `heap.Push`.
+func (pq *priorityHeap[T, P]) Push(x interface{}) {
+	item := x.(*heapItem[T, P])
+	item.index = len(pq.items)
+	pq.items = append(pq.items, item)
+}
+
+// Pop removes an item from the heap. Pop should not be called directly;
+// instead, use `heap.Pop`.
+func (pq *priorityHeap[T, P]) Pop() interface{} {
+	n := len(pq.items)
+	item := pq.items[n-1]
+	pq.items[n-1] = nil // avoid memory leak
+	item.index = -1
+	pq.items = pq.items[0:(n - 1)]
+	return item
+}
+
+// Peek returns the item at the beginning of the heap, without removing the
+// item or otherwise mutating the heap. It is safe to call directly.
+func (pq *priorityHeap[T, P]) Peek() *heapItem[T, P] {
+	return pq.items[0]
+}
+
+// 가장 나중에 꺼내질 아이템을 반환한다. O(N/2)
+// 힙에서 가장 뒤의 아이템은 항상 leaf 이므로 leaf만 검사한다. 힙이 비어있으면 안된다.
+func (pq *priorityHeap[T, P]) Worst() *heapItem[T, P] {
+	n := len(pq.items)
+	worst := pq.items[n-1]
+	for _, item := range pq.items[n/2:] {
+		if pq.before(worst, item) {
+			worst = item
+		}
+	}
+	return worst
+}
+
+// 힙을 비운다.
+func (pq *priorityHeap[T, P]) Clear() {
+	for _, item := range pq.items {
+		item.index = -1
+	}
+	pq.items = nil
+}
+
+// 컴파일 타임에 heap.Interface 구현 여부 검사
+var _ heap.Interface = (*priorityHeap[int, int])(nil)
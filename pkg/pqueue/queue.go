@@ -0,0 +1,144 @@
+package pqueue
+
+import (
+	"context"
+
+	"gostudy/pkg/queue"
+)
+
+// 컴파일 타임에 인터페이스 구현 여부 검사
+var (
+	_ queue.BlockingQueue[Item[int, int]] = (*PriorityChannel[int, int])(nil)
+	_ DelayingInterface[int]              = (*DelayingQueue[int])(nil)
+	_ DelayingInterface[int]              = (*TimingWheel[int])(nil)
+)
+
+/////////////////////////////////////////////////////////////////////////
+// PriorityChannel: queue.BlockingQueue[Item[T, P]]
+// 추가할 때는 아이템의 Data, Priority만 사용한다.
+/////////////////////////////////////////////////////////////////////////
+
+// 아이템을 추가한다(대기하지 않음)
+// 닫혔으면 ErrClosed, 가득찼으면 ErrFull(혹은 오버플로 정책에 따라 ErrDropped)
+func (c *PriorityChannel[T, P]) Offer(item Item[T, P]) error {
+	_, err := c.tryPush(item.Data, item.Priority)
+	return err
+}
+
+// 아이템을 꺼낸다(대기하지 않음)
+// 비어있으면 ErrEmpty, 비어있고 닫혔으면 ErrClosed
+func (c *PriorityChannel[T, P]) Poll() (item Item[T, P], err error) {
+	c.wLock()
+	defer c.wUnlock()
+
+	x, err := c.pop()
+	if err != nil {
+		return item, err
+	}
+	return x.item(c.clock.Now()), nil
+}
+
+// 아이템을 추가한다(오버플로 정책이 Block이면 자리가 날 때까지 대기)
+// ctx가 종료되면 ctx.Err()
+func (c *PriorityChannel[T, P]) Put(ctx context.Context, item Item[T, P]) error {
+	c.rLock()
+	policy := c.overflow
+	c.rUnlock()
+
+	if policy != Block {
+		return c.Offer(item)
+	}
+	_, err := c.pushContext(ctx, item.Data, item.Priority)
+	return err
+}
+
+// 아이템을 꺼낸다(아이템이 있을때 까지 대기)
+// ctx가 종료되면 ctx.Err(), 비어있고 닫혔으면 ErrClosed
+func (c *PriorityChannel[T, P]) Take(ctx context.Context) (item Item[T, P], err error) {
+	x, err := c.popContext(ctx)
+	if err != nil {
+		return item, err
+	}
+	return x.item(c.clock.Now()), nil
+}
+
+// 채널의 현재 원소 갯수(Count)
+func (c *PriorityChannel[T, P]) Len() int {
+	return c.Count()
+}
+
+/////////////////////////////////////////////////////////////////////////
+// DelayingQueue: queue.BlockingQueue[T]
+// 준비된(ready) 아이템만 꺼내며, 크기 제한이 없으므로 추가할 때 대기하지 않는다.
+/////////////////////////////////////////////////////////////////////////
+
+// 아이템을 바로 추가한다(Add)
+// 종료되었으면 ErrShutDown
+func (q *DelayingQueue[T]) Offer(item T) error {
+	return q.Add(item)
+}
+
+// 준비된 아이템을 꺼낸다(대기하지 않음)
+// 준비된 아이템이 없으면 queue.ErrEmpty, 종료되었으면 ErrShutDown
+func (q *DelayingQueue[T]) Poll() (item T, err error) {
+	q.l.Lock()
+	defer q.l.Unlock()
+	return q.poll()
+}
+
+// 아이템을 바로 추가한다(Add). 크기 제한이 없으므로 대기하지 않는다.
+// ctx가 이미 종료되었으면 ctx.Err()
+func (q *DelayingQueue[T]) Put(ctx context.Context, item T) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return q.Add(item)
+}
+
+// 준비된 아이템을 꺼낸다(Get)
+func (q *DelayingQueue[T]) Take(ctx context.Context) (T, error) {
+	return q.Get(ctx)
+}
+
+// 큐를 종료한다(ShutDown)
+func (q *DelayingQueue[T]) Close() {
+	q.ShutDown()
+}
+
+/////////////////////////////////////////////////////////////////////////
+// TimingWheel: queue.BlockingQueue[T]
+// DelayingQueue와 같다.
+/////////////////////////////////////////////////////////////////////////
+
+// 아이템을 바로 추가한다(Add)
+// 종료되었으면 ErrShutDown
+func (w *TimingWheel[T]) Offer(item T) error {
+	return w.Add(item)
+}
+
+// 준비된 아이템을 꺼낸다(대기하지 않음)
+// 준비된 아이템이 없으면 queue.ErrEmpty, 종료되었으면 ErrShutDown
+func (w *TimingWheel[T]) Poll() (item T, err error) {
+	w.l.Lock()
+	defer w.l.Unlock()
+	return w.poll()
+}
+
+// 아이템을 바로 추가한다(Add). 크기 제한이 없으므로 대기하지 않는다.
+// ctx가 이미 종료되었으면 ctx.Err()
+func (w *TimingWheel[T]) Put(ctx context.Context, item T) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return w.Add(item)
+}
+
+// 준비된 아이템을 꺼낸다(Get)
+func (w *TimingWheel[T]) Take(ctx context.Context) (T, error) {
+	return w.Get(ctx)
+}
+
+// 큐를 종료한다(ShutDown)
+func (w *TimingWheel[T]) Close() {
+	w.ShutDown()
+}
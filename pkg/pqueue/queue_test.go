@@ -0,0 +1,38 @@
+package pqueue
+
+import (
+	"testing"
+
+	"gostudy/pkg/queue"
+	"gostudy/pkg/queue/queuetest"
+)
+
+// PriorityChannel은 우선순위 순(같으면 입력 순)으로 꺼내는 queue.BlockingQueue 이다
+func TestPriorityChannelConformance(t *testing.T) {
+	queuetest.Run(t, queuetest.Config[Item[int, int]]{
+		New: func(cap int) queue.Queue[Item[int, int]] {
+			return NewChannel[int](cap)
+		},
+		Item: func(i int) Item[int, int] {
+			return Item[int, int]{Data: i, Priority: (i * 7) % 3}
+		},
+		Index: func(item Item[int, int]) int {
+			return item.Data
+		},
+		Less: func(a, b Item[int, int]) bool {
+			return a.Priority < b.Priority
+		},
+	})
+}
+
+// DelayingQueue는 준비된 아이템을 입력 순으로 꺼내는 크기 제한 없는 queue.BlockingQueue 이다
+func TestDelayingQueueConformance(t *testing.T) {
+	queuetest.Run(t, queuetest.Config[int]{
+		New: func(int) queue.Queue[int] {
+			return NewDelayingQueue[int]()
+		},
+		Item:      func(i int) int { return i },
+		Index:     func(item int) int { return item },
+		Unbounded: true,
+	})
+}
@@ -0,0 +1,166 @@
+package pqueue
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"gostudy/pkg/clock"
+)
+
+/////////////////////////////////////////////////////////////////////////////
+// from github.com/kubernetes/client-go/util/workqueue/default_rate_limiters.go
+/////////////////////////////////////////////////////////////////////////////
+
+// 재시도(requeue) 지연 시간을 결정하는 rate limiter
+type RateLimiter[T comparable] interface {
+	// 아이템을 다시 추가하기 전에 기다려야 하는 시간
+	When(item T) time.Duration
+	// 아이템의 재시도 기록을 지운다(처리 성공 혹은 포기)
+	Forget(item T)
+	// 아이템의 재시도 횟수
+	NumRequeues(item T) int
+}
+
+// client-go의 기본 rate limiter
+// 아이템별 지수 backoff(5ms ~ 1000s)와 전체 token bucket(10 qps, burst 100) 중 긴 지연을 사용한다.
+func DefaultRateLimiter[T comparable]() RateLimiter[T] {
+	return NewMaxOfRateLimiter[T](
+		NewItemExponentialFailureRateLimiter[T](time.Millisecond*5, time.Second*1000),
+		NewBucketRateLimiter[T](10, 100),
+	)
+}
+
+// 아이템별 지수 backoff rate limiter
+// 실패할 때마다 base * 2^(실패횟수) 만큼 기다리며, 최대 max 까지 기다린다.
+type ItemExponentialFailureRateLimiter[T comparable] struct {
+	l        sync.Mutex
+	failures map[T]int
+	base     time.Duration
+	max      time.Duration
+}
+
+// 아이템별 지수 backoff rate limiter 생성
+func NewItemExponentialFailureRateLimiter[T comparable](base, max time.Duration) *ItemExponentialFailureRateLimiter[T] {
+	return &ItemExponentialFailureRateLimiter[T]{
+		failures: map[T]int{},
+		base:     base,
+		max:      max,
+	}
+}
+
+func (r *ItemExponentialFailureRateLimiter[T]) When(item T) time.Duration {
+	r.l.Lock()
+	defer r.l.Unlock()
+
+	exp := r.failures[item]
+	r.failures[item]++
+
+	// overflow 방지
+	backoff := float64(r.base) * math.Pow(2, float64(exp))
+	if backoff > float64(r.max) {
+		return r.max
+	}
+	return time.Duration(backoff)
+}
+
+func (r *ItemExponentialFailureRateLimiter[T]) Forget(item T) {
+	r.l.Lock()
+	defer r.l.Unlock()
+	delete(r.failures, item)
+}
+
+func (r *ItemExponentialFailureRateLimiter[T]) NumRequeues(item T) int {
+	r.l.Lock()
+	defer r.l.Unlock()
+	return r.failures[item]
+}
+
+// 전체 아이템에 대한 token bucket rate limiter
+// 초당 qps 개의 토큰이 채워지며, 최대 burst 개 까지 쌓인다.
+// 토큰이 없으면 다음 토큰이 채워질 때까지의 시간을 반환한다.(예약)
+type BucketRateLimiter[T comparable] struct {
+	l      sync.Mutex
+	qps    float64   // 초당 토큰 수
+	burst  int       // 최대 토큰 수
+	tokens float64   // 현재 토큰 수(음수: 예약된 토큰)
+	last   time.Time // 마지막으로 토큰을 채운 시간
+	clock  clock.Clock
+}
+
+// token bucket rate limiter 생성
+func NewBucketRateLimiter[T comparable](qps float64, burst int) *BucketRateLimiter[T] {
+	return NewBucketRateLimiterWithClock[T](qps, burst, clock.Real)
+}
+
+// 주어진 시계로 토큰을 채우는 token bucket rate limiter 생성(테스트에서 clock.Fake 사용)
+func NewBucketRateLimiterWithClock[T comparable](qps float64, burst int, c clock.Clock) *BucketRateLimiter[T] {
+	return &BucketRateLimiter[T]{
+		qps:    qps,
+		burst:  burst,
+		tokens: float64(burst),
+		last:   c.Now(),
+		clock:  c,
+	}
+}
+
+func (r *BucketRateLimiter[T]) When(item T) time.Duration {
+	r.l.Lock()
+	defer r.l.Unlock()
+
+	// 지난 시간 만큼 토큰을 채운다
+	now := r.clock.Now()
+	r.tokens = math.Min(r.tokens+now.Sub(r.last).Seconds()*r.qps, float64(r.burst))
+	r.last = now
+
+	// 토큰을 하나 사용(예약)
+	r.tokens--
+	if r.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-r.tokens / r.qps * float64(time.Second))
+}
+
+// token bucket은 아이템별 기록이 없다
+func (r *BucketRateLimiter[T]) Forget(item T) {}
+
+// token bucket은 아이템별 기록이 없다
+func (r *BucketRateLimiter[T]) NumRequeues(item T) int {
+	return 0
+}
+
+// 여러 rate limiter 중 가장 긴 지연을 사용하는 rate limiter
+type MaxOfRateLimiter[T comparable] struct {
+	limiters []RateLimiter[T]
+}
+
+// 여러 rate limiter 중 가장 긴 지연을 사용하는 rate limiter 생성
+func NewMaxOfRateLimiter[T comparable](limiters ...RateLimiter[T]) *MaxOfRateLimiter[T] {
+	return &MaxOfRateLimiter[T]{limiters: limiters}
+}
+
+func (r *MaxOfRateLimiter[T]) When(item T) time.Duration {
+	var max time.Duration
+	for _, limiter := range r.limiters {
+		if d := limiter.When(item); d > max {
+			max = d
+		}
+	}
+	return max
+}
+
+func (r *MaxOfRateLimiter[T]) Forget(item T) {
+	for _, limiter := range r.limiters {
+		limiter.Forget(item)
+	}
+}
+
+func (r *MaxOfRateLimiter[T]) NumRequeues(item T) int {
+	var max int
+	for _, limiter := range r.limiters {
+		if n := limiter.NumRequeues(item); n > max {
+			max = n
+		}
+	}
+	return max
+}
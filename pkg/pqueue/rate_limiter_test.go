@@ -0,0 +1,75 @@
+package pqueue
+
+import (
+	"testing"
+	"time"
+
+	"gostudy/pkg/clock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 지수 backoff: 실패할 때마다 지연이 두배가 되며 max를 넘지 않는다
+func TestItemExponentialFailureRateLimiterShouldBackoffPerItem(t *testing.T) {
+	assert := assert.New(t)
+	limiter := NewItemExponentialFailureRateLimiter[string](time.Millisecond, time.Second)
+
+	for _, expected := range []time.Duration{1, 2, 4, 8, 16} {
+		assert.Equal(expected*time.Millisecond, limiter.When("a"))
+	}
+	assert.Equal(5, limiter.NumRequeues("a"))
+
+	// 아이템별로 따로 계산된다
+	assert.Equal(time.Millisecond, limiter.When("b"))
+	assert.Equal(1, limiter.NumRequeues("b"))
+
+	// Forget 후에는 처음부터
+	limiter.Forget("a")
+	assert.Zero(limiter.NumRequeues("a"))
+	assert.Equal(time.Millisecond, limiter.When("a"))
+
+	// max를 넘지 않는다(overflow 포함)
+	for i := 0; i < 100; i++ {
+		limiter.When("c")
+	}
+	assert.Equal(time.Second, limiter.When("c"))
+}
+
+// token bucket: burst 만큼은 바로 허용하고, 이후에는 1/qps 간격으로 예약된다
+func TestBucketRateLimiterShouldLimitOverallRate(t *testing.T) {
+	assert := assert.New(t)
+	fake := clock.NewFake(epoch)
+	limiter := NewBucketRateLimiterWithClock[int](10, 3, fake)
+
+	for i := 0; i < 3; i++ {
+		assert.Zero(limiter.When(i))
+	}
+	for i := 1; i <= 3; i++ {
+		assert.Equal(time.Millisecond*100*time.Duration(i), limiter.When(i))
+	}
+	assert.Zero(limiter.NumRequeues(1))
+
+	// 1초 동안 10개가 채워져 예약된 3개를 갚고, burst(3) 까지만 쌓인다
+	fake.Advance(time.Second)
+	for i := 0; i < 3; i++ {
+		assert.Zero(limiter.When(i))
+	}
+	assert.Equal(time.Millisecond*100, limiter.When(0))
+}
+
+// 여러 rate limiter 중 가장 긴 지연을 사용한다
+func TestMaxOfRateLimiterShouldUseLongestDelay(t *testing.T) {
+	assert := assert.New(t)
+	limiter := NewMaxOfRateLimiter[string](
+		NewItemExponentialFailureRateLimiter[string](time.Millisecond, time.Hour),
+		NewBucketRateLimiterWithClock[string](1, 2, clock.NewFake(epoch)),
+	)
+
+	assert.Equal(time.Millisecond, limiter.When("a"))   // bucket: 0
+	assert.Equal(time.Millisecond*2, limiter.When("a")) // bucket: 0
+	assert.Equal(time.Second, limiter.When("a"))        // bucket: 1s
+	assert.Equal(3, limiter.NumRequeues("a"))
+
+	limiter.Forget("a")
+	assert.Zero(limiter.NumRequeues("a"))
+}
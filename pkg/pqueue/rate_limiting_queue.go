@@ -0,0 +1,101 @@
+package pqueue
+
+import (
+	"context"
+	"time"
+
+	"gostudy/pkg/clock"
+)
+
+// 재시도(requeue) 지연 시간을 지정할 수 있는 작업 큐
+//
+// client-go의 rate limiting queue와 같이, WorkQueue에 지연 추가(AddAfter)와
+// rate limiter에 따른 재시도(AddRateLimited)를 더한 것이다.
+// 지연 아이템은 DelayingQueue에서 대기하다가 readyAt이 지나면 WorkQueue에 추가된다.
+//
+//	item, err := q.Get(ctx)
+//	if err := process(item); err != nil {
+//		q.AddRateLimited(item) // 실패: backoff 후 재시도
+//	} else {
+//		q.Forget(item) // 성공: 재시도 기록 삭제
+//	}
+//	q.Done(item)
+type RateLimitingQueue[T comparable] struct {
+	*WorkQueue[T]
+	delaying *DelayingQueue[T]
+	limiter  RateLimiter[T]
+	stopped  chan struct{} // 지연 아이템 전달 고루틴 종료됨
+}
+
+// rate limiting 작업 큐 생성
+// 사용 후 반드시 ShutDown 혹은 ShutDownWithDrain을 호출하여 고루틴을 종료한다.
+func NewRateLimitingQueue[T comparable](limiter RateLimiter[T]) *RateLimitingQueue[T] {
+	return NewRateLimitingQueueWithClock[T](limiter, clock.Real)
+}
+
+// 주어진 시계로 지연 시간을 재는 rate limiting 작업 큐 생성(테스트에서 clock.Fake 사용)
+// rate limiter도 시계를 사용한다면(NewBucketRateLimiterWithClock) 같은 시계를 지정한다.
+func NewRateLimitingQueueWithClock[T comparable](limiter RateLimiter[T], c clock.Clock) *RateLimitingQueue[T] {
+	q := &RateLimitingQueue[T]{
+		WorkQueue: NewWorkQueue[T](),
+		delaying:  NewDelayingQueueWithClock[T](c),
+		limiter:   limiter,
+		stopped:   make(chan struct{}),
+	}
+	go q.forward()
+	return q
+}
+
+// d 만큼 지난 후에 아이템을 추가한다.
+func (q *RateLimitingQueue[T]) AddAfter(item T, d time.Duration) {
+	if q.ShuttingDown() {
+		return
+	}
+	if d <= 0 {
+		q.Add(item)
+		return
+	}
+	q.delaying.AddAfter(item, d)
+}
+
+// rate limiter가 허락하는 시간이 지난 후에 아이템을 추가한다.
+func (q *RateLimitingQueue[T]) AddRateLimited(item T) {
+	q.AddAfter(item, q.limiter.When(item))
+}
+
+// 아이템의 재시도 기록을 지운다(처리 성공 혹은 포기)
+func (q *RateLimitingQueue[T]) Forget(item T) {
+	q.limiter.Forget(item)
+}
+
+// 아이템의 재시도 횟수
+func (q *RateLimitingQueue[T]) NumRequeues(item T) int {
+	return q.limiter.NumRequeues(item)
+}
+
+// 큐를 종료한다. 아직 지연 대기중인 아이템은 버린다.
+func (q *RateLimitingQueue[T]) ShutDown() {
+	q.delaying.ShutDown()
+	<-q.stopped
+	q.WorkQueue.ShutDown()
+}
+
+// 큐를 종료하고, 처리중인 아이템이 모두 Done 될 때까지 대기한다.
+// 아직 지연 대기중인 아이템은 버린다.
+func (q *RateLimitingQueue[T]) ShutDownWithDrain() {
+	q.delaying.ShutDown()
+	<-q.stopped
+	q.WorkQueue.ShutDownWithDrain()
+}
+
+// readyAt이 지난 지연 아이템을 작업 큐에 추가하는 고루틴
+func (q *RateLimitingQueue[T]) forward() {
+	defer close(q.stopped)
+	for {
+		item, err := q.delaying.Get(context.Background())
+		if err != nil {
+			return
+		}
+		q.Add(item)
+	}
+}
@@ -0,0 +1,101 @@
+package pqueue
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+
+	"gostudy/pkg/clock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 실패한 작업은 backoff 후에 다시 처리되고, 성공하면 재시도 기록이 지워진다
+func TestRateLimitingQueueShouldRetryFailedItemWithBackoff(t *testing.T) {
+	assert := assert.New(t)
+	fake := clock.NewFake(epoch)
+	q := NewRateLimitingQueueWithClock[string](NewItemExponentialFailureRateLimiter[string](time.Millisecond*10, time.Second), fake)
+	defer q.ShutDown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	// 3번 실패 후 성공하는 작업
+	failures := 3
+	process := func(item string) error {
+		if failures > 0 {
+			failures--
+			return errors.New("failed")
+		}
+		return nil
+	}
+
+	q.Add("job")
+	for {
+		item, err := q.Get(ctx)
+		assert.NoError(err)
+		if err != nil {
+			return
+		}
+
+		if err := process(item); err != nil {
+			// backoff 시간이 지나야 다시 처리된다
+			backoff := time.Millisecond * 10 << q.NumRequeues(item)
+			q.AddRateLimited(item)
+			q.Done(item)
+			fake.Advance(backoff - time.Nanosecond)
+			assert.Zero(q.Len())
+			fake.Advance(time.Nanosecond)
+			continue
+		}
+
+		assert.Equal(3, q.NumRequeues(item))
+		q.Forget(item)
+		q.Done(item)
+		break
+	}
+
+	// 10ms + 20ms + 40ms
+	assert.Equal(time.Millisecond*70, fake.Since(epoch))
+	assert.Zero(q.NumRequeues("job"))
+}
+
+// 지연 대기중인 아이템도 작업 큐의 dedup 규칙을 따른다
+func TestRateLimitingQueueAddAfterShouldDeduplicate(t *testing.T) {
+	assert := assert.New(t)
+	fake := clock.NewFake(epoch)
+	q := NewRateLimitingQueueWithClock[string](DefaultRateLimiter[string](), fake)
+	defer q.ShutDown()
+
+	q.AddAfter("a", time.Millisecond*10)
+	q.AddAfter("a", time.Millisecond*20)
+	q.AddAfter("b", 0)
+	assert.Equal(1, q.Len())
+
+	fake.Advance(time.Millisecond * 20)
+	// 지연 아이템 전달 고루틴이 옮길 때까지 대기
+	deadline := time.Now().Add(time.Second)
+	for q.Len() < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	assert.Equal(2, q.Len())
+}
+
+// ShutDown은 지연 아이템 전달 고루틴과 타이머 고루틴을 종료한다
+func TestRateLimitingQueueShutDownShouldNotLeakGoroutines(t *testing.T) {
+	assert := assert.New(t)
+	before := runtime.NumGoroutine()
+
+	q := NewRateLimitingQueue[int](DefaultRateLimiter[int]())
+	q.AddAfter(1, time.Hour)
+	q.Add(2)
+	q.ShutDown()
+
+	assert.True(q.ShuttingDown())
+	q.AddRateLimited(3)
+	assert.Equal(1, q.Len())
+
+	assertNoGoroutineLeak(t, before)
+}
@@ -0,0 +1,357 @@
+package pqueue
+
+import (
+	"context"
+	"math"
+	"math/bits"
+	"sync"
+	"time"
+
+	"gostudy/pkg/clock"
+	"gostudy/pkg/queue"
+)
+
+// 타이밍 휠 크기
+// 레벨마다 64개의 슬롯이 있고, 레벨이 올라갈 때마다 슬롯의 크기(tick 수)가 64배가 된다.
+// 예: tick이 1ms 이면 레벨 0은 64ms, 레벨 5는 64^6ms(약 2년)를 담는다. 그보다 먼 아이템은 overflow에 보관한다.
+const (
+	wheelBits   = 6
+	wheelSlots  = 1 << wheelBits
+	wheelMask   = wheelSlots - 1
+	wheelLevels = 6
+)
+
+// 타이밍 휠에서 readyAt을 기다리는 아이템
+type wheelEntry[T any] struct {
+	data T
+	tick int64 // 준비되는 tick(readyAt을 tick 단위로 올림)
+}
+
+// 계층형 타이밍 휠(hierarchical timing wheel) 지연 큐
+//
+// DelayingQueue는 대기중인 아이템을 힙(waitForPriorityQueue)에 보관하므로 추가, 꺼내기가 O(logN) 이다.
+// TimingWheel은 시간을 tick 단위로 나누고, 준비되는 tick에 따라 아이템을 레벨별 슬롯(bucket)에 넣는다.
+//   - 추가: 현재 tick과 준비 tick이 처음으로 달라지는 (64진법) 자리수의 레벨, 그 자리 값의 슬롯에 넣는다. O(1)
+//   - 진행: 높은 레벨 슬롯의 시작 tick이 되면 그 슬롯의 아이템을 아래 레벨로 옮기고(cascade),
+//     레벨 0 슬롯의 아이템은 준비된 아이템으로 옮긴다. 아이템은 최대 레벨 수만큼만 옮겨지므로 O(1)
+//   - 아무 일도 없는 tick은 건너뛰고, 처리할 슬롯이 있는 tick에만 타이머 고루틴이 깨어난다.
+//
+// 대신 readyAt은 tick 단위로 올림되므로, 아이템은 최대 1 tick 늦게 준비된다.
+// 사용법은 DelayingQueue와 같다. 사용 후 반드시 ShutDown을 호출하여 타이머 고루틴을 종료한다.
+type TimingWheel[T any] struct {
+	l        sync.Mutex
+	clock    clock.Clock
+	tick     time.Duration // tick 크기(해상도)
+	start    time.Time     // tick 0의 시간
+	current  int64         // 마지막으로 처리한 tick
+	wheels   [wheelLevels][wheelSlots][]wheelEntry[T]
+	overflow []wheelEntry[T] // 가장 높은 레벨보다 먼 아이템
+	waiting  int             // readyAt을 기다리는 아이템 수
+	ready    []T             // 준비된 아이템(FIFO)
+	wakeAt   int64           // 타이머 고루틴이 깨어날 tick(math.MaxInt64: 잠듦)
+	notify   chan struct{}   // ready 큐 상태 변경 알림
+	wake     chan struct{}   // 타이머 고루틴 깨우기(더 빨리 처리할 슬롯이 생김)
+	stop     chan struct{}   // 타이머 고루틴 종료
+	stopped  chan struct{}   // 타이머 고루틴 종료됨
+	closed   bool            // 큐 종료
+}
+
+// tick 해상도의 타이밍 휠 지연 큐 생성(타이머 고루틴 시작)
+// tick이 0 이하면 1ms
+func NewTimingWheel[T any](tick time.Duration) *TimingWheel[T] {
+	return NewTimingWheelWithClock[T](tick, clock.Real)
+}
+
+// 시계를 지정하여 타이밍 휠 지연 큐 생성(테스트에서는 clock.Fake 사용)
+func NewTimingWheelWithClock[T any](tick time.Duration, c clock.Clock) *TimingWheel[T] {
+	if tick <= 0 {
+		tick = time.Millisecond
+	}
+	w := &TimingWheel[T]{
+		clock:   c,
+		tick:    tick,
+		start:   c.Now(),
+		wakeAt:  math.MaxInt64,
+		wake:    make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go w.loop()
+	return w
+}
+
+// 아이템을 바로 추가한다.
+func (w *TimingWheel[T]) Add(item T) error {
+	return w.AddAt(item, time.Time{})
+}
+
+// d 만큼 지난 후에 아이템을 추가한다.
+func (w *TimingWheel[T]) AddAfter(item T, d time.Duration) error {
+	if d <= 0 {
+		return w.Add(item)
+	}
+	return w.AddAt(item, w.clock.Now().Add(d))
+}
+
+// readyAt 시각에 아이템을 추가한다. 이미 지난 시각이면 바로 추가한다.
+func (w *TimingWheel[T]) AddAt(item T, readyAt time.Time) error {
+	w.l.Lock()
+	defer w.l.Unlock()
+
+	if w.closed {
+		return ErrShutDown
+	}
+
+	if !readyAt.After(w.clock.Now()) {
+		w.ready = append(w.ready, item)
+		w.broadcast()
+		return nil
+	}
+
+	entry := wheelEntry[T]{data: item, tick: w.ceilTick(readyAt)}
+	w.waiting++
+	w.insert(entry)
+
+	// 타이머 고루틴이 더 늦게 깨어날 예정이면 깨운다
+	if entry.tick < w.wakeAt {
+		select {
+		case w.wake <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// 준비된 아이템을 하나 꺼낸다(준비된 아이템이 있을때 까지 대기)
+// ctx가 종료되면 ctx.Err(), 큐가 종료되고 준비된 아이템이 없으면 ErrShutDown
+func (w *TimingWheel[T]) Get(ctx context.Context) (item T, err error) {
+	for {
+		w.l.Lock()
+		item, err = w.poll()
+		if err != queue.ErrEmpty {
+			w.l.Unlock()
+			return item, err
+		}
+		changed := w.changed()
+		w.l.Unlock()
+
+		// wait for ready item
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return item, ctx.Err()
+		}
+	}
+}
+
+// 준비된 아이템을 하나 꺼낸다. 락을 잡은 상태에서 호출
+// 준비된 아이템이 없으면 queue.ErrEmpty, 큐가 종료되었으면 ErrShutDown
+func (w *TimingWheel[T]) poll() (item T, err error) {
+	if len(w.ready) > 0 {
+		item = w.ready[0]
+		var zero T
+		w.ready[0] = zero // avoid memory leak
+		w.ready = w.ready[1:]
+		return item, nil
+	}
+	if w.closed {
+		return item, ErrShutDown
+	}
+	return item, queue.ErrEmpty
+}
+
+// 준비된 아이템 수
+func (w *TimingWheel[T]) Len() int {
+	w.l.Lock()
+	defer w.l.Unlock()
+	return len(w.ready)
+}
+
+// readyAt을 기다리는 아이템 수
+func (w *TimingWheel[T]) Waiting() int {
+	w.l.Lock()
+	defer w.l.Unlock()
+	return w.waiting
+}
+
+// 큐를 종료한다. 타이머 고루틴을 종료하고 대기중인(readyAt 이전) 아이템은 버린다.
+// 이미 준비된 아이템은 Get으로 꺼낼 수 있다. 여러번 호출해도 안전하다.
+func (w *TimingWheel[T]) ShutDown() {
+	w.l.Lock()
+	if w.closed {
+		w.l.Unlock()
+		<-w.stopped
+		return
+	}
+	w.closed = true
+	w.wheels = [wheelLevels][wheelSlots][]wheelEntry[T]{}
+	w.overflow = nil
+	w.waiting = 0
+	w.broadcast()
+	w.l.Unlock()
+
+	close(w.stop)
+	<-w.stopped
+}
+
+// 큐가 종료되었나?
+func (w *TimingWheel[T]) ShuttingDown() bool {
+	w.l.Lock()
+	defer w.l.Unlock()
+	return w.closed
+}
+
+// t 시간을 tick으로 변환(올림)
+func (w *TimingWheel[T]) ceilTick(t time.Time) int64 {
+	d := t.Sub(w.start)
+	tick := int64(d / w.tick)
+	if d%w.tick > 0 {
+		tick++
+	}
+	return tick
+}
+
+// t 시간을 tick으로 변환(내림)
+func (w *TimingWheel[T]) floorTick(t time.Time) int64 {
+	return int64(t.Sub(w.start) / w.tick)
+}
+
+// tick의 시작 시간
+func (w *TimingWheel[T]) timeOf(tick int64) time.Time {
+	return w.start.Add(time.Duration(tick) * w.tick)
+}
+
+// 아이템을 준비 tick에 맞는 레벨의 슬롯에 넣는다. 이미 준비된 아이템은 ready 큐로 옮긴다. 락을 잡은 상태에서 호출
+//
+// 현재 tick과 준비 tick을 64진법으로 비교하여 처음으로 달라지는 자리수(가장 높은 자리)를 레벨로 사용한다.
+// 그 슬롯의 시작 tick은 항상 현재 tick 이후이고, 그 때 아래 레벨로 옮겨진다(cascade).
+func (w *TimingWheel[T]) insert(entry wheelEntry[T]) {
+	if entry.tick <= w.current {
+		w.waiting--
+		w.ready = append(w.ready, entry.data)
+		w.broadcast()
+		return
+	}
+
+	level := (bits.Len64(uint64(entry.tick^w.current)) - 1) / wheelBits
+	if level >= wheelLevels {
+		w.overflow = append(w.overflow, entry)
+		return
+	}
+	slot := (entry.tick >> (wheelBits * level)) & wheelMask
+	w.wheels[level][slot] = append(w.wheels[level][slot], entry)
+}
+
+// 다음으로 처리할 슬롯의 시작 tick을 반환한다(없으면 math.MaxInt64). 락을 잡은 상태에서 호출
+// 레벨마다 현재 슬롯 이후의 첫번째 아이템이 있는 슬롯을 찾는다. O(levels * slots)
+func (w *TimingWheel[T]) nextTick() int64 {
+	next := int64(math.MaxInt64)
+	if len(w.overflow) > 0 {
+		const shift = wheelBits * wheelLevels
+		next = ((w.current >> shift) + 1) << shift
+	}
+
+	for level := 0; level < wheelLevels; level++ {
+		shift := wheelBits * level
+		base := w.current >> shift
+		// 높은 레벨일수록 다음 슬롯의 시작 tick이 늦으므로 더 찾을 필요가 없다
+		if (base+1)<<shift >= next {
+			break
+		}
+		for k := int64(1); k < wheelSlots; k++ {
+			if len(w.wheels[level][(base+k)&wheelMask]) > 0 {
+				if start := (base + k) << shift; start < next {
+					next = start
+				}
+				break
+			}
+		}
+	}
+	return next
+}
+
+// target tick까지 시간을 진행시킨다. 락을 잡은 상태에서 호출
+// 아무 일도 없는 tick은 건너뛰고, 처리할 슬롯이 있는 tick만 차례로 처리한다.
+func (w *TimingWheel[T]) advance(target int64) {
+	for w.current < target {
+		next := w.nextTick()
+		if next > target {
+			w.current = target
+			return
+		}
+		w.current = next
+		w.expire(next)
+	}
+}
+
+// tick에 시작하는 슬롯들을 처리한다. 락을 잡은 상태에서 호출
+// 높은 레벨부터 슬롯의 아이템을 아래 레벨로 옮기고, 레벨 0 슬롯의 아이템은 준비된 아이템으로 옮긴다.
+func (w *TimingWheel[T]) expire(tick int64) {
+	if tick&(1<<(wheelBits*wheelLevels)-1) == 0 {
+		entries := w.overflow
+		w.overflow = nil
+		for _, entry := range entries {
+			w.insert(entry)
+		}
+	}
+
+	for level := wheelLevels - 1; level >= 0; level-- {
+		shift := wheelBits * level
+		if tick&(1<<shift-1) != 0 {
+			continue
+		}
+		slot := (tick >> shift) & wheelMask
+		entries := w.wheels[level][slot]
+		w.wheels[level][slot] = nil
+		for _, entry := range entries {
+			w.insert(entry)
+		}
+	}
+}
+
+// 타이머 고루틴
+// 다음으로 처리할 슬롯의 시작 시간에 깨어나 시간을 진행시킨다.
+// 대기중인 아이템이 없으면 새 아이템이 추가될 때까지 잠든다.
+func (w *TimingWheel[T]) loop() {
+	defer close(w.stopped)
+
+	timer := w.clock.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		var next <-chan time.Time
+
+		w.l.Lock()
+		w.advance(w.floorTick(w.clock.Now()))
+		w.wakeAt = w.nextTick()
+		if w.wakeAt != math.MaxInt64 {
+			// 절대 시간으로 설정(가짜 시계에서 Now와 Reset 사이에 시간이 흘러도 정확)
+			clock.ResetTimerAt(timer, w.timeOf(w.wakeAt))
+			next = timer.C()
+		}
+		w.l.Unlock()
+
+		select {
+		case <-w.stop:
+			return
+		case <-next:
+		case <-w.wake:
+		}
+	}
+}
+
+// ready 큐 상태 변경 알림 채널을 반환한다. 락을 잡은 상태에서 호출
+func (w *TimingWheel[T]) changed() <-chan struct{} {
+	if w.notify == nil {
+		w.notify = make(chan struct{})
+	}
+	return w.notify
+}
+
+// ready 큐 상태 변경을 기다리는 고루틴을 모두 깨운다. 락을 잡은 상태에서 호출
+func (w *TimingWheel[T]) broadcast() {
+	if w.notify != nil {
+		close(w.notify)
+		w.notify = nil
+	}
+}
@@ -0,0 +1,231 @@
+package pqueue
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+	"time"
+
+	"gostudy/pkg/clock"
+	"gostudy/pkg/queue"
+	"gostudy/pkg/queue/queuetest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var epoch = time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)
+
+// 타이머 고루틴이 준비된 아이템을 옮길 때까지(최대 1초) 기다린 후 모두 꺼낸다
+func drainReady[T any](q DelayingInterface[T], n int) []T {
+	items := []T{}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	for len(items) < n {
+		item, err := q.Get(ctx)
+		if err != nil {
+			break
+		}
+		items = append(items, item)
+	}
+	// 더 준비된 아이템이 없어야 한다
+	for {
+		item, err := q.Poll()
+		if err != nil {
+			return items
+		}
+		items = append(items, item)
+	}
+}
+
+// 시간이 흘러 readyAt이 지나면 준비된다(모든 레벨, overflow 포함)
+func TestTimingWheelShouldBeReadyAfterDelay(t *testing.T) {
+	assert := assert.New(t)
+
+	fake := clock.NewFake(epoch)
+	w := NewTimingWheelWithClock[string](time.Millisecond, fake)
+	defer w.ShutDown()
+
+	delays := []struct {
+		data  string
+		delay time.Duration
+	}{
+		{"level0", time.Millisecond * 5},
+		{"level1", time.Millisecond * 100},
+		{"level2", time.Second * 10},
+		{"level3", time.Hour * 3},
+		{"level5", time.Hour * 24 * 30},
+		{"overflow", time.Hour * 24 * 1000},
+	}
+	for _, d := range delays {
+		assert.NoError(w.AddAfter(d.data, d.delay))
+	}
+	assert.Equal(len(delays), w.Waiting())
+	assert.Equal(0, w.Len())
+
+	for i, d := range delays {
+		// 1 tick 전에는 준비되지 않는다
+		fake.Set(epoch.Add(d.delay - time.Millisecond))
+		assert.Empty(drainReady[string](w, 0), d.data)
+
+		fake.Set(epoch.Add(d.delay))
+		assert.Equal([]string{d.data}, drainReady[string](w, 1))
+		assert.Equal(len(delays)-i-1, w.Waiting())
+	}
+}
+
+// 무작위 지연의 아이템들을 무작위 간격으로 시간을 진행시켜도, 준비 시간이 지난 아이템만 정확히 준비된다
+func TestTimingWheelShouldCascadeRandomDelays(t *testing.T) {
+	assert := assert.New(t)
+
+	fake := clock.NewFake(epoch)
+	w := NewTimingWheelWithClock[int](time.Millisecond, fake)
+	defer w.ShutDown()
+
+	// ms 단위 지연(최대 2시간)
+	const n = 2000
+	delays := make([]int, n)
+	for i := range delays {
+		delays[i] = rand.Intn(int(time.Hour*2/time.Millisecond)) + 1
+		assert.NoError(w.AddAfter(i, time.Duration(delays[i])*time.Millisecond))
+	}
+	order := rand.Perm(n)
+	sort.Slice(order, func(i, j int) bool { return delays[order[i]] < delays[order[j]] })
+
+	elapsed, next := 0, 0
+	for next < n {
+		elapsed += rand.Intn(int(time.Minute*10/time.Millisecond)) + 1
+		fake.Set(epoch.Add(time.Duration(elapsed) * time.Millisecond))
+
+		expected := []int{}
+		for ; next < n && delays[order[next]] <= elapsed; next++ {
+			expected = append(expected, order[next])
+		}
+		if !assert.ElementsMatch(expected, drainReady[int](w, len(expected)), "elapsed=%dms", elapsed) {
+			return
+		}
+	}
+	assert.Zero(w.Waiting())
+}
+
+// 타이머 고루틴이 잠든 뒤에 더 빠른 아이템이 추가되면 다시 깨어난다
+func TestTimingWheelShouldWakeUpForEarlierItem(t *testing.T) {
+	assert := assert.New(t)
+
+	fake := clock.NewFake(epoch)
+	w := NewTimingWheelWithClock[string](time.Millisecond, fake)
+	defer w.ShutDown()
+
+	assert.NoError(w.AddAfter("late", time.Hour))
+	fake.Advance(time.Minute)
+	assert.NoError(w.AddAfter("early", time.Second))
+
+	fake.Advance(time.Second)
+	assert.Equal([]string{"early"}, drainReady[string](w, 1))
+	assert.Equal(1, w.Waiting())
+
+	// 이미 지난 시각이나 0 이하의 지연은 바로 준비된다
+	assert.NoError(w.AddAt("past", epoch))
+	assert.NoError(w.AddAfter("now", 0))
+	assert.Equal([]string{"past", "now"}, drainReady[string](w, 2))
+}
+
+// 해상도(tick)보다 짧은 지연은 다음 tick에 준비된다
+func TestTimingWheelShouldRoundUpToTick(t *testing.T) {
+	assert := assert.New(t)
+
+	fake := clock.NewFake(epoch)
+	w := NewTimingWheelWithClock[string](time.Second, fake)
+	defer w.ShutDown()
+
+	assert.NoError(w.AddAfter("a", time.Millisecond*1500))
+	fake.Advance(time.Millisecond * 1500)
+	assert.Empty(drainReady[string](w, 0))
+	fake.Advance(time.Millisecond * 500)
+	assert.Equal([]string{"a"}, drainReady[string](w, 1))
+}
+
+// ShutDown 후에는 추가할 수 없고, 대기중인 아이템은 버려진다
+func TestTimingWheelShutDown(t *testing.T) {
+	assert := assert.New(t)
+
+	fake := clock.NewFake(epoch)
+	w := NewTimingWheelWithClock[int](time.Millisecond, fake)
+	assert.NoError(w.Add(1))
+	assert.NoError(w.AddAfter(2, time.Second))
+
+	w.ShutDown()
+	w.ShutDown()
+	assert.True(w.ShuttingDown())
+	assert.Zero(w.Waiting())
+	assert.ErrorIs(w.Add(3), ErrShutDown)
+
+	item, err := w.Get(context.Background())
+	assert.NoError(err)
+	assert.Equal(1, item)
+	_, err = w.Get(context.Background())
+	assert.ErrorIs(err, ErrShutDown)
+}
+
+// TimingWheel은 준비된 아이템을 입력 순으로 꺼내는 크기 제한 없는 queue.BlockingQueue 이다
+func TestTimingWheelConformance(t *testing.T) {
+	queuetest.Run(t, queuetest.Config[int]{
+		New: func(int) queue.Queue[int] {
+			return NewTimingWheel[int](time.Millisecond)
+		},
+		Item:      func(i int) int { return i },
+		Index:     func(item int) int { return item },
+		Unbounded: true,
+	})
+}
+
+// 지연 큐 구현
+var delayingQueues = []struct {
+	name string
+	new  func() DelayingInterface[int]
+}{
+	{"heap", func() DelayingInterface[int] { return NewDelayingQueue[int]() }},
+	{"wheel", func() DelayingInterface[int] { return NewTimingWheel[int](time.Millisecond) }},
+}
+
+// 대기중인 아이템이 많을 때 추가 비용: 힙 O(logN), 타이밍 휠 O(1)
+func BenchmarkDelayingAddAfter(b *testing.B) {
+	for _, waiting := range []int{1000, 1000000} {
+		for _, q := range delayingQueues {
+			b.Run(fmt.Sprintf("%s/waiting=%d", q.name, waiting), func(b *testing.B) {
+				dq := q.new()
+				defer dq.ShutDown()
+				for i := 0; i < waiting; i++ {
+					dq.AddAfter(i, time.Hour+time.Duration(rand.Int63n(int64(time.Hour))))
+				}
+
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					dq.AddAfter(i, time.Minute+time.Duration(rand.Int63n(int64(time.Hour))))
+				}
+			})
+		}
+	}
+}
+
+// 짧은 지연(최대 10ms)의 아이템을 추가하고 모두 꺼낼 때까지의 처리량(재시도 큐와 같은 사용)
+func BenchmarkDelayingRetry(b *testing.B) {
+	for _, q := range delayingQueues {
+		b.Run(q.name, func(b *testing.B) {
+			dq := q.new()
+			defer dq.ShutDown()
+
+			go func() {
+				for i := 0; i < b.N; i++ {
+					dq.AddAfter(i, time.Duration(rand.Int63n(int64(time.Millisecond*10))))
+				}
+			}()
+			for i := 0; i < b.N; i++ {
+				if _, err := dq.Get(context.Background()); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
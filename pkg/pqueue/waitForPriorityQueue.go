@@ -1,4 +1,4 @@
-package main
+package pqueue
 
 /////////////////////////////////////////////////////////////////////////
 // from github.com/kubernetes/client-go/util/workqueue/delaying_queue.go
@@ -1,16 +1,38 @@
-package main
+package pqueue
 
 import (
 	"container/heap"
+	"flag"
 	"runtime"
 	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/stretchr/testify/assert"
 )
 
+// 고루틴마다 실행하는 push/pop 횟수
+// 실제 시간(10초) 대신 횟수로 제한하여 테스트가 바로 끝난다.
+const waitForOps = 100000
+
+// 동시성을 보장하지 않는 큐를 여러 고루틴에서 사용하는 테스트는 패키지 테스트를 망가뜨릴 수 있으므로
+// -unsafe 를 지정했을 때만 실행한다. -race 와 함께 실행하면 race detector가 테스트를 실패시킨다.
+//
+//	go test ./pkg/pqueue -run ^TestWaitForPriorityQueueIsConcurrentUnsafe$ -unsafe
+var runUnsafe = flag.Bool("unsafe", false, "run tests that use a concurrent-unsafe queue from multiple goroutines")
+
+// x번째 고루틴이 n번째로 추가하는 아이템의 readyAt(실제 시계 대신 고정된 시각 사용)
+func readyAt(x, n int) time.Time {
+	return epoch.Add(time.Duration(n*97+x) * time.Microsecond)
+}
+
 // waitForPriorityQueue는 동시성을 보장하지 않는다.
+// 고루틴의 panic은 recover로 잡아서 기록한다(스케줄링에 따라 panic 없이 끝날 수도 있다).
 func TestWaitForPriorityQueueIsConcurrentUnsafe(t *testing.T) {
+	if !*runUnsafe {
+		t.Skip("concurrent-unsafe test: run with -unsafe")
+	}
 
 	// 우선순위 큐 생성 및 초기화
 	pq := &waitForPriorityQueue{}
@@ -20,23 +42,26 @@ func TestWaitForPriorityQueueIsConcurrentUnsafe(t *testing.T) {
 	workers := runtime.NumCPU() * 2
 	runtime.GOMAXPROCS(workers)
 
-	// timeout trigger
-	done := false
-	go func(d time.Duration) {
-		<-time.After(d) // wait for timeout
-		done = true
-	}(time.Second * 10)
-
 	// pushed, poped count
 	pushed, poped := uint64(0), uint64(0)
 
+	// 고루틴에서 발생한 panic(처음 하나)
+	var panicked interface{}
+	var once sync.Once
+	recoverPanic := func() {
+		if r := recover(); r != nil {
+			once.Do(func() { panicked = r })
+		}
+	}
+
 	// channel push goroutine
 	wg.Add(workers)
 	for i := 0; i < workers; i++ {
 		go func(x int) {
 			defer wg.Done()
-			for !done {
-				heap.Push(pq, &waitFor{data: x, readyAt: time.Now()})
+			defer recoverPanic()
+			for n := 0; n < waitForOps; n++ {
+				heap.Push(pq, &waitFor{data: x, readyAt: readyAt(x, n)})
 				atomic.AddUint64(&pushed, 1) // concurrent safe
 			}
 		}(i)
@@ -47,7 +72,8 @@ func TestWaitForPriorityQueueIsConcurrentUnsafe(t *testing.T) {
 	for i := 0; i < workers; i++ {
 		go func(x int) {
 			defer wg.Done()
-			for !done {
+			defer recoverPanic()
+			for n := 0; n < waitForOps; n++ {
 				if pq.Len() > 0 {
 					heap.Pop(pq)
 				}
@@ -61,8 +87,13 @@ func TestWaitForPriorityQueueIsConcurrentUnsafe(t *testing.T) {
 
 	// print push, pop action count
 	t.Logf("push: %d, pop: %d", pushed, poped)
+	if panicked != nil {
+		t.Logf("panic: %v", panicked)
+	} else {
+		t.Log("no panic this time (the queue is still concurrent-unsafe)")
+	}
 
-	/* OUTPUT
+	/* OUTPUT(recover 하지 않았을 때)
 	D:\gitworks\go-study\priority-queue>go test -v -run ^TestWaitForPriorityQueueIsConcurrentUnsafe$
 	=== RUN   TestWaitForPriorityQueueIsConcurrentUnsafe
 	panic: runtime error: invalid memory address or nil pointer dereference
@@ -96,13 +127,6 @@ func TestWaitForPriorityQueueIsConcurrentSafe(t *testing.T) {
 	workers := runtime.NumCPU() * 2
 	runtime.GOMAXPROCS(workers)
 
-	// timeout trigger
-	done := false
-	go func(d time.Duration) {
-		<-time.After(d) // wait for timeout
-		done = true
-	}(time.Second * 10)
-
 	// pushed, poped count
 	pushed, poped := uint64(0), uint64(0)
 
@@ -110,13 +134,13 @@ func TestWaitForPriorityQueueIsConcurrentSafe(t *testing.T) {
 	wg.Add(workers)
 	for i := 0; i < workers; i++ {
 		go func(x int) {
-			for !done {
+			for n := 0; n < waitForOps; n++ {
 				func() {
 					////////////////////
 					// CRITICAL SECTION
 					lock.Lock()
 					defer lock.Unlock()
-					heap.Push(pq, &waitFor{data: x, readyAt: time.Now()})
+					heap.Push(pq, &waitFor{data: x, readyAt: readyAt(x, n)})
 					pushed++
 					////////////////////
 					// waitForPriorityQueue.Push() 내부에서 크리티컬 섹션을 구현하는 편이 좋음
@@ -131,7 +155,7 @@ func TestWaitForPriorityQueueIsConcurrentSafe(t *testing.T) {
 	wg.Add(workers)
 	for i := 0; i < workers; i++ {
 		go func(x int) {
-			for !done {
+			for n := 0; n < waitForOps; n++ {
 				func() {
 					///////////////////////
 					// CRITICAL SECTION
@@ -156,6 +180,16 @@ func TestWaitForPriorityQueueIsConcurrentSafe(t *testing.T) {
 	// print push, pop action count
 	t.Logf("push: %d, pop: %d", pushed, poped)
 
+	// 모든 push가 반영되고, 남은 아이템은 readyAt 순으로 꺼내진다
+	assert.Equal(t, uint64(workers*waitForOps), pushed)
+	assert.Equal(t, int(pushed-poped), pq.Len())
+	var prev time.Time
+	for pq.Len() > 0 {
+		item := heap.Pop(pq).(*waitFor)
+		assert.False(t, item.readyAt.Before(prev))
+		prev = item.readyAt
+	}
+
 	/* OUTPUT
 	D:\gitworks\go-study\priority-queue>go test -v -run ^TestWaitForPriorityQueueIsConcurrentSafe$
 	=== RUN   TestWaitForPriorityQueueIsConcurrentSafe
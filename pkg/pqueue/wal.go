@@ -0,0 +1,447 @@
+package pqueue
+
+import (
+	"bufio"
+	"bytes"
+	"container/heap"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+////////////////////////////////////////////////////////////////////////////
+// 우선순위 채널의 WAL(write-ahead log) 영속화
+//
+// push, pop 등 채널의 상태를 바꾸는 모든 작업을 먼저 WAL 파일에 기록하고,
+// 주기적으로 현재 상태를 스냅샷 파일로 저장한 뒤 WAL을 비운다(compaction).
+// 다시 시작하면 스냅샷을 읽고 WAL을 재생하여 같은 우선순위, 입력 순서(seq)를 복구한다.
+//
+// 파일 포맷: [길이(4바이트)][crc32(4바이트)][레코드] 의 반복
+// 마지막 레코드를 쓰는 중에 프로세스가 죽은 경우(길이 부족, crc 불일치)
+// 해당 레코드부터는 버린다.
+////////////////////////////////////////////////////////////////////////////
+
+const (
+	walFileName      = "wal.log"
+	snapshotFileName = "snapshot"
+	walHeaderSize    = 8
+	walMaxRecordSize = 1 << 26 // 64MB, 이보다 크면 깨진 레코드로 판단
+
+	defaultSnapshotEvery = 1000
+)
+
+// 레코드 직렬화 codec
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// encoding/gob codec
+type GobCodec struct{}
+
+func (GobCodec) Marshal(v interface{}) ([]byte, error) {
+	buf := bytes.Buffer{}
+	err := gob.NewEncoder(&buf).Encode(v)
+	return buf.Bytes(), err
+}
+
+func (GobCodec) Unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// encoding/json codec
+type JSONCodec struct{}
+
+func (JSONCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (JSONCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// WAL 옵션
+type WALOptions struct {
+	Codec         Codec // 레코드 codec(기본값: GobCodec)
+	SnapshotEvery int   // 레코드 N개 마다 스냅샷 저장(기본값: 1000)
+	Sync          bool  // 레코드마다 fsync(느리지만 OS crash에도 안전)
+}
+
+// WAL 레코드 종류
+type walOp uint8
+
+const (
+	walPush   walOp = iota + 1 // 아이템 추가(seq, data, priority, enqueuedAt)
+	walPop                     // 아이템 꺼냄(seq)
+	walUpdate                  // 우선순위 변경(seq, priority)
+	walRemove                  // 아이템 삭제(seq)
+	walClear                   // 모든 아이템 삭제(CloseNow)
+)
+
+// WAL 레코드
+type walRecord[T, P any] struct {
+	Op         walOp
+	Seq        uint64
+	Data       T
+	Priority   P
+	EnqueuedAt time.Time
+}
+
+// WAL 파일
+type wal[T, P any] struct {
+	dir     string
+	file    *os.File
+	opts    WALOptions
+	records int // 마지막 스냅샷 이후 기록된 레코드 수
+}
+
+// WAL을 사용하는 우선순위 채널을 연다.
+// dir에 이전 WAL, 스냅샷이 있으면 복구하여 같은 우선순위, 입력 순서로 채널을 재구성한다.
+// 복구한 아이템이 cap보다 많으면(더 작은 cap으로 다시 연 경우) 파일을 그대로 두고 ErrFull을 반환한다.
+// 사용 후에는 CloseWAL을 호출하여 파일을 닫는다.
+func OpenChannel[T, P any](dir string, cap int, less func(a, b P) bool, opts WALOptions) (*PriorityChannel[T, P], error) {
+	if opts.Codec == nil {
+		opts.Codec = GobCodec{}
+	}
+	if opts.SnapshotEvery <= 0 {
+		opts.SnapshotEvery = defaultSnapshotEvery
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	w := &wal[T, P]{dir: dir, opts: opts}
+	items, seq, err := w.recover()
+	if err != nil {
+		return nil, err
+	}
+	if len(items) > cap {
+		w.file.Close()
+		return nil, fmt.Errorf("%w: recovered %d items exceed cap %d", ErrFull, len(items), cap)
+	}
+
+	c := NewChannelFunc[T](cap, less)
+	for _, item := range items {
+		heap.Push(&c.q, item)
+	}
+	c.seq = seq
+	c.wal = w
+
+	// 복구한 상태로 스냅샷을 새로 만든다(WAL 정리)
+	if err := w.compact(c.q.items); err != nil {
+		w.file.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// 스냅샷을 저장하고 WAL을 비운다.
+func (c *PriorityChannel[T, P]) Compact() error {
+	c.wLock()
+	defer c.wUnlock()
+
+	if c.wal == nil {
+		return nil
+	}
+	return c.wal.compact(c.q.items)
+}
+
+// 스냅샷을 저장하고 WAL 파일을 닫는다. 이후 채널은 메모리에서만 동작한다.
+func (c *PriorityChannel[T, P]) CloseWAL() error {
+	c.wLock()
+	defer c.wUnlock()
+
+	if c.wal == nil {
+		return nil
+	}
+	err := c.wal.compact(c.q.items)
+	if cerr := c.wal.file.Close(); err == nil {
+		err = cerr
+	}
+	c.wal = nil
+	return err
+}
+
+// WAL에 레코드를 기록한다. 락을 잡은 상태에서 호출
+// WAL을 사용하지 않으면 아무것도 하지 않는다.
+func (c *PriorityChannel[T, P]) log(op walOp, item *heapItem[T, P]) error {
+	if c.wal == nil {
+		return nil
+	}
+	return c.wal.append(record(op, item))
+}
+
+// 아이템의 WAL 레코드
+func record[T, P any](op walOp, item *heapItem[T, P]) walRecord[T, P] {
+	// pop, remove 등은 seq만 기록한다
+	rec := walRecord[T, P]{Op: op}
+	if item != nil {
+		rec.Seq = item.seq
+	}
+	switch op {
+	case walPush:
+		rec.Data = item.data
+		rec.EnqueuedAt = item.enqueuedAt
+		rec.Priority = item.priority
+	case walUpdate:
+		rec.Priority = item.priority
+	}
+	return rec
+}
+
+// 버릴 아이템의 삭제와 추가할 아이템들을 WAL에 한번에 기록한다. 락을 잡은 상태에서 호출
+// 모두 기록되거나 하나도 기록되지 않는다. WAL을 사용하지 않으면 아무것도 하지 않는다.
+func (c *PriorityChannel[T, P]) logBatch(removed, pushed []*heapItem[T, P]) error {
+	if c.wal == nil {
+		return nil
+	}
+
+	recs := make([]walRecord[T, P], 0, len(removed)+len(pushed))
+	for _, item := range removed {
+		recs = append(recs, record(walRemove, item))
+	}
+	for _, item := range pushed {
+		recs = append(recs, record(walPush, item))
+	}
+	return c.wal.appendAll(recs)
+}
+
+// 레코드가 충분히 쌓였으면 스냅샷을 저장한다. 락을 잡은 상태에서 호출
+// 실패해도 WAL은 그대로 남아있으므로, 다음 기회에 다시 시도한다.
+func (c *PriorityChannel[T, P]) checkpoint() {
+	if c.wal == nil || c.wal.records < c.wal.opts.SnapshotEvery {
+		return
+	}
+	c.wal.compact(c.q.items)
+}
+
+// 레코드를 WAL 파일 끝에 추가한다.
+func (w *wal[T, P]) append(rec walRecord[T, P]) error {
+	frame, err := w.frame(rec)
+	if err != nil {
+		return err
+	}
+	if _, err := w.file.Write(frame); err != nil {
+		return err
+	}
+	if w.opts.Sync {
+		if err := w.file.Sync(); err != nil {
+			return err
+		}
+	}
+	w.records++
+	return nil
+}
+
+// 여러 레코드를 한번에 WAL 파일 끝에 추가한다.
+// 쓰는 도중에 실패하면 기록하기 전의 길이로 되돌리므로, 모두 기록되거나 하나도 기록되지 않는다.
+// (되돌리기 전에 죽더라도 복구할 때 끝의 깨진 레코드는 잘라낸다)
+func (w *wal[T, P]) appendAll(recs []walRecord[T, P]) error {
+	var frames []byte
+	for _, rec := range recs {
+		frame, err := w.frame(rec)
+		if err != nil {
+			return err
+		}
+		frames = append(frames, frame...)
+	}
+
+	offset, err := w.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	_, err = w.file.Write(frames)
+	if err == nil && w.opts.Sync {
+		err = w.file.Sync()
+	}
+	if err != nil {
+		w.file.Truncate(offset)
+		w.file.Seek(offset, io.SeekStart)
+		return err
+	}
+	w.records += len(recs)
+	return nil
+}
+
+// 레코드를 [길이][crc32][레코드] 형식으로 만든다.
+func (w *wal[T, P]) frame(rec walRecord[T, P]) ([]byte, error) {
+	payload, err := w.opts.Codec.Marshal(rec)
+	if err != nil {
+		return nil, err
+	}
+	frame := make([]byte, walHeaderSize+len(payload))
+	binary.BigEndian.PutUint32(frame[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(frame[4:8], crc32.ChecksumIEEE(payload))
+	copy(frame[walHeaderSize:], payload)
+	return frame, nil
+}
+
+// 현재 아이템으로 스냅샷을 저장하고 WAL을 비운다.
+//
+// 스냅샷은 임시 파일에 쓴 뒤 rename 하므로, 쓰는 도중에 죽어도 이전 스냅샷이 남는다.
+// rename 후 WAL을 비우기 전에 죽으면 이미 스냅샷에 반영된 레코드가 다시 재생되지만,
+// 레코드 재생은 seq 기준으로 멱등(idempotent)하므로 결과는 같다.
+func (w *wal[T, P]) compact(items []*heapItem[T, P]) error {
+	tmp := filepath.Join(w.dir, snapshotFileName+".tmp")
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(f)
+	for _, item := range items {
+		frame, err := w.frame(walRecord[T, P]{
+			Op:         walPush,
+			Seq:        item.seq,
+			Data:       item.data,
+			Priority:   item.priority,
+			EnqueuedAt: item.enqueuedAt,
+		})
+		if err == nil {
+			_, err = bw.Write(frame)
+		}
+		if err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, filepath.Join(w.dir, snapshotFileName)); err != nil {
+		return err
+	}
+
+	// WAL 비우기
+	if err := w.file.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := w.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	w.records = 0
+	return nil
+}
+
+// 스냅샷과 WAL을 읽어 아이템을 복구한다.
+// 복구한 아이템과 지금까지 사용한 가장 큰 seq를 반환한다.
+// WAL 끝의 깨진 레코드는 잘라내고, 이어서 기록할 수 있도록 WAL 파일을 열어둔다.
+func (w *wal[T, P]) recover() ([]*heapItem[T, P], uint64, error) {
+	items := map[uint64]*heapItem[T, P]{}
+	var maxSeq uint64
+
+	replay := func(rec walRecord[T, P]) {
+		if rec.Seq > maxSeq {
+			maxSeq = rec.Seq
+		}
+		switch rec.Op {
+		case walPush:
+			if _, ok := items[rec.Seq]; !ok {
+				items[rec.Seq] = &heapItem[T, P]{
+					element:    element[T, P]{data: rec.Data, priority: rec.Priority},
+					effective:  rec.Priority,
+					enqueuedAt: rec.EnqueuedAt,
+					seq:        rec.Seq,
+				}
+			}
+		case walUpdate:
+			if item, ok := items[rec.Seq]; ok {
+				item.priority = rec.Priority
+				item.effective = rec.Priority
+			}
+		case walPop, walRemove:
+			delete(items, rec.Seq)
+		case walClear:
+			items = map[uint64]*heapItem[T, P]{}
+		}
+	}
+
+	// 스냅샷
+	if f, err := os.Open(filepath.Join(w.dir, snapshotFileName)); err == nil {
+		_, err = w.read(f, replay)
+		f.Close()
+		if err != nil {
+			return nil, 0, err
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, 0, err
+	}
+
+	// WAL
+	f, err := os.OpenFile(filepath.Join(w.dir, walFileName), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, 0, err
+	}
+	valid, err := w.read(f, replay)
+	if err == nil {
+		// 깨진 레코드 잘라내기
+		err = f.Truncate(valid)
+	}
+	if err == nil {
+		_, err = f.Seek(valid, io.SeekStart)
+	}
+	if err != nil {
+		f.Close()
+		return nil, 0, err
+	}
+	w.file = f
+
+	result := make([]*heapItem[T, P], 0, len(items))
+	for _, item := range items {
+		result = append(result, item)
+	}
+	return result, maxSeq, nil
+}
+
+// 파일의 레코드를 차례로 읽어 fn을 호출한다.
+// 온전하게 읽은 마지막 레코드까지의 크기(offset)를 반환한다.
+func (w *wal[T, P]) read(r io.Reader, fn func(rec walRecord[T, P])) (int64, error) {
+	br := bufio.NewReader(r)
+	header := make([]byte, walHeaderSize)
+	var offset int64
+
+	for {
+		if _, err := io.ReadFull(br, header); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return offset, nil // 끝, 혹은 헤더를 쓰다가 죽음
+			}
+			return offset, err
+		}
+
+		size := binary.BigEndian.Uint32(header[0:4])
+		if size > walMaxRecordSize {
+			return offset, nil // 깨진 레코드
+		}
+		payload := make([]byte, size)
+		if _, err := io.ReadFull(br, payload); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return offset, nil // 레코드를 쓰다가 죽음
+			}
+			return offset, err
+		}
+		if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(header[4:8]) {
+			return offset, nil // 깨진 레코드
+		}
+
+		var rec walRecord[T, P]
+		if err := w.opts.Codec.Unmarshal(payload, &rec); err != nil {
+			return offset, fmt.Errorf("wal: invalid record: %w", err)
+		}
+		fn(rec)
+		offset += int64(walHeaderSize + len(payload))
+	}
+}
@@ -0,0 +1,245 @@
+package pqueue
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gostudy/pkg/rng"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// WAL 테스트용 작업
+type walJob struct {
+	ID   int
+	Name string
+}
+
+// WAL 채널을 연다
+func openWALChannel(t *testing.T, dir string, opts WALOptions) *PriorityChannel[walJob, int] {
+	pc, err := OpenChannel[walJob](dir, 10000, Less[int], opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pc
+}
+
+// 테스트용 codec: fail이 true인 레코드는 기록하지 못한다
+type failingCodec struct {
+	GobCodec
+	fail func(rec walRecord[walJob, int]) bool
+}
+
+func (c failingCodec) Marshal(v interface{}) ([]byte, error) {
+	if rec, ok := v.(walRecord[walJob, int]); ok && c.fail(rec) {
+		return nil, errors.New("marshal failed")
+	}
+	return c.GobCodec.Marshal(v)
+}
+
+// 채널의 아이템을 모두 꺼내서 순서대로 반환한다
+func drainAll[T, P any](pc *PriorityChannel[T, P]) []T {
+	items := []T{}
+	for pc.Count() > 0 {
+		data, err := pc.Deque()
+		if err != nil {
+			break
+		}
+		items = append(items, data)
+	}
+	return items
+}
+
+// 프로세스가 죽은 후(파일을 닫지 않고) 다시 열면 같은 우선순위, 입력 순서로 복구된다
+func TestWALShouldRecoverPriorityAndInputOrder(t *testing.T) {
+	for name, codec := range map[string]Codec{"gob": GobCodec{}, "json": JSONCodec{}} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			dir := t.TempDir()
+			opts := WALOptions{Codec: codec, SnapshotEvery: 50}
+
+			pc := openWALChannel(t, dir, opts)
+			handles := []*Handle[walJob, int]{}
+			for i := 0; i < 200; i++ {
+				h, err := pc.Push(walJob{ID: i, Name: rng.NextString(rng.Alphabet, 4)}, rng.NextInRange(0, 10))
+				assert.NoError(err)
+				handles = append(handles, h)
+			}
+			for i := 0; i < 30; i++ {
+				_, err := pc.Pop()
+				assert.NoError(err)
+			}
+			for i := 0; i < 200; i += 7 {
+				handles[i].Update(rng.NextInRange(0, 10))
+			}
+			for i := 3; i < 200; i += 11 {
+				handles[i].Remove()
+			}
+
+			// crash: CloseWAL 없이 다시 연다
+			recovered := openWALChannel(t, dir, opts)
+			defer recovered.CloseWAL()
+			assert.Equal(pc.Count(), recovered.Count())
+			assert.Equal(drainAll(pc), drainAll(recovered))
+		})
+	}
+}
+
+// 복구 후 새로 추가한 아이템은 같은 우선순위의 기존 아이템 뒤에 위치한다
+func TestWALShouldResumeSequenceAfterRecovery(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+
+	pc := openWALChannel(t, dir, WALOptions{})
+	assert.NoError(push(pc, walJob{ID: 1}, 1))
+	assert.NoError(push(pc, walJob{ID: 2}, 1))
+	_, err := pc.Pop()
+	assert.NoError(err)
+	assert.NoError(pc.CloseWAL())
+
+	pc = openWALChannel(t, dir, WALOptions{})
+	defer pc.CloseWAL()
+	assert.NoError(push(pc, walJob{ID: 3}, 1))
+	assert.NoError(push(pc, walJob{ID: 4}, 0))
+	assert.Equal([]walJob{{ID: 4}, {ID: 2}, {ID: 3}}, drainAll(pc))
+}
+
+// 레코드를 쓰는 도중에 죽으면(잘린 레코드, 깨진 레코드) 마지막 온전한 레코드까지 복구된다
+func TestWALShouldDiscardTornRecordOnCrash(t *testing.T) {
+	walSize := func(dir string) int64 {
+		st, err := os.Stat(filepath.Join(dir, walFileName))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return st.Size()
+	}
+
+	for name, corrupt := range map[string]func(path string, size int64){
+		// 레코드 헤더만 기록됨
+		"header": func(path string, size int64) { os.Truncate(path, size+3) },
+		// 레코드 일부만 기록됨
+		"payload": func(path string, size int64) { os.Truncate(path, size+walHeaderSize+2) },
+		// 레코드가 깨짐
+		"crc": func(path string, size int64) {
+			b, _ := os.ReadFile(path)
+			b[len(b)-1] ^= 0xff
+			os.WriteFile(path, b, 0o644)
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			dir := t.TempDir()
+
+			pc := openWALChannel(t, dir, WALOptions{})
+			assert.NoError(push(pc, walJob{ID: 1}, 2))
+			assert.NoError(push(pc, walJob{ID: 2}, 1))
+			size := walSize(dir)
+			assert.NoError(push(pc, walJob{ID: 3}, 0)) // 이 레코드를 쓰다가 죽었다
+
+			corrupt(filepath.Join(dir, walFileName), size)
+
+			recovered := openWALChannel(t, dir, WALOptions{})
+			assert.Equal(2, recovered.Count())
+
+			// 깨진 레코드를 잘라냈으므로 이어서 기록한 레코드도 복구된다
+			assert.NoError(push(recovered, walJob{ID: 4}, 3))
+			recovered = openWALChannel(t, dir, WALOptions{})
+			defer recovered.CloseWAL()
+			assert.Equal([]walJob{{ID: 2}, {ID: 1}, {ID: 4}}, drainAll(recovered))
+		})
+	}
+}
+
+// 복구한 아이템이 cap보다 많으면 열지 않고, 파일은 그대로 남는다
+func TestWALShouldRejectRecoveryOverCap(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+
+	pc := openWALChannel(t, dir, WALOptions{})
+	for i := 0; i < 3; i++ {
+		assert.NoError(push(pc, walJob{ID: i}, i))
+	}
+
+	_, err := OpenChannel[walJob](dir, 2, Less[int], WALOptions{})
+	assert.ErrorIs(err, ErrFull)
+
+	pc, err = OpenChannel[walJob](dir, 3, Less[int], WALOptions{})
+	assert.NoError(err)
+	defer pc.CloseWAL()
+	assert.Equal(3, pc.Count())
+}
+
+// CloseNow가 WAL에 기록하지 못하면 에러를 반환하고, 아이템은 버리지 않는다
+func TestWALCloseNowShouldReturnLogError(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	opts := WALOptions{Codec: failingCodec{fail: func(rec walRecord[walJob, int]) bool {
+		return rec.Op == walClear
+	}}}
+
+	pc := openWALChannel(t, dir, opts)
+	assert.NoError(push(pc, walJob{ID: 1}, 1))
+	n, err := pc.CloseNow()
+	assert.Error(err)
+	assert.Zero(n)
+	assert.True(pc.Closed())
+	assert.Equal(1, pc.Count())
+	assert.NoError(pc.CloseWAL())
+
+	// 디스크의 상태와 같다
+	pc = openWALChannel(t, dir, WALOptions{})
+	defer pc.CloseWAL()
+	assert.Equal([]walJob{{ID: 1}}, drainAll(pc))
+}
+
+// 스냅샷 후 WAL을 비우기 전에 죽으면, 스냅샷에 반영된 레코드가 다시 재생되어도 결과는 같다
+func TestWALShouldReplayIdempotentlyAfterCompactionCrash(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, walFileName)
+
+	pc := openWALChannel(t, dir, WALOptions{SnapshotEvery: 1 << 20})
+	for i := 0; i < 10; i++ {
+		assert.NoError(push(pc, walJob{ID: i}, i%3))
+	}
+	_, err := pc.Pop()
+	assert.NoError(err)
+
+	// 스냅샷 직전의 WAL
+	log, err := os.ReadFile(path)
+	assert.NoError(err)
+	assert.NoError(pc.Compact())
+
+	st, err := os.Stat(path)
+	assert.NoError(err)
+	assert.Zero(st.Size())
+
+	// WAL을 비우지 못하고 죽었다
+	assert.NoError(os.WriteFile(path, log, 0o644))
+
+	recovered := openWALChannel(t, dir, WALOptions{})
+	defer recovered.CloseWAL()
+	assert.Equal(9, recovered.Count())
+	assert.Equal(drainAll(pc), drainAll(recovered))
+}
+
+// 레코드가 SnapshotEvery 만큼 쌓이면 스냅샷을 저장하고 WAL을 비운다
+func TestWALShouldCompactPeriodically(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+
+	pc := openWALChannel(t, dir, WALOptions{SnapshotEvery: 10})
+	for i := 0; i < 95; i++ {
+		assert.NoError(push(pc, walJob{ID: i}, 0))
+	}
+	assert.Equal(5, pc.wal.records)
+
+	_, err := os.Stat(filepath.Join(dir, snapshotFileName))
+	assert.NoError(err)
+
+	recovered := openWALChannel(t, dir, WALOptions{})
+	defer recovered.CloseWAL()
+	assert.Equal(95, recovered.Count())
+}
@@ -0,0 +1,160 @@
+package pqueue
+
+import (
+	"context"
+	"sync"
+)
+
+////////////////////////////////////////////////////////////////////
+// from github.com/kubernetes/client-go/util/workqueue/queue.go
+////////////////////////////////////////////////////////////////////
+
+// 작업 큐(work queue)
+//
+// client-go의 workqueue와 같은 규칙을 따른다.
+//   - 대기중인 아이템을 다시 추가하면 한번만 처리된다.(dedup)
+//   - 처리중인(Get 이후 Done 이전) 아이템을 다시 추가하면, Done 호출 후 다시 큐에 추가된다.
+//     따라서 같은 아이템이 동시에 두 워커에서 처리되지 않는다.
+//   - ShutDown 후에는 추가할 수 없고, 남은 아이템을 모두 꺼내면 Get이 ErrShutDown을 반환한다.
+type WorkQueue[T comparable] struct {
+	l          sync.Mutex
+	queue      []T            // 처리 순서(FIFO)
+	dirty      map[T]struct{} // 처리가 필요한 아이템(queue + 처리중에 다시 추가된 아이템)
+	processing map[T]struct{} // 처리중인 아이템
+	notify     chan struct{}  // 상태 변경 알림
+
+	shuttingDown bool // 큐 종료
+}
+
+// 작업 큐 생성
+func NewWorkQueue[T comparable]() *WorkQueue[T] {
+	return &WorkQueue[T]{
+		dirty:      map[T]struct{}{},
+		processing: map[T]struct{}{},
+	}
+}
+
+// 아이템을 추가한다.
+// 이미 대기중이면 무시하고, 처리중이면 Done 호출 후 다시 추가한다.
+func (q *WorkQueue[T]) Add(item T) {
+	q.l.Lock()
+	defer q.l.Unlock()
+
+	if q.shuttingDown {
+		return
+	}
+	if _, ok := q.dirty[item]; ok {
+		return
+	}
+
+	q.dirty[item] = struct{}{}
+	if _, ok := q.processing[item]; ok {
+		return
+	}
+
+	q.queue = append(q.queue, item)
+	q.broadcast()
+}
+
+// 처리할 아이템을 하나 꺼낸다(아이템이 있을때 까지 대기)
+// 꺼낸 아이템은 처리 후 반드시 Done을 호출해야 한다.
+// ctx가 종료되면 ctx.Err(), 큐가 종료되고 남은 아이템이 없으면 ErrShutDown
+func (q *WorkQueue[T]) Get(ctx context.Context) (item T, err error) {
+	for {
+		q.l.Lock()
+		if len(q.queue) > 0 {
+			item = q.queue[0]
+			var zero T
+			q.queue[0] = zero // avoid memory leak
+			q.queue = q.queue[1:]
+
+			q.processing[item] = struct{}{}
+			delete(q.dirty, item)
+			q.l.Unlock()
+			return item, nil
+		}
+		if q.shuttingDown {
+			q.l.Unlock()
+			return item, ErrShutDown
+		}
+		changed := q.changed()
+		q.l.Unlock()
+
+		// wait for item
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return item, ctx.Err()
+		}
+	}
+}
+
+// 아이템의 처리가 끝났음을 알린다.
+// 처리중에 다시 추가된 아이템이면 다시 큐에 추가한다.
+func (q *WorkQueue[T]) Done(item T) {
+	q.l.Lock()
+	defer q.l.Unlock()
+
+	delete(q.processing, item)
+	if _, ok := q.dirty[item]; ok {
+		q.queue = append(q.queue, item)
+	}
+	q.broadcast()
+}
+
+// 대기중인 아이템 수(처리중인 아이템 제외)
+func (q *WorkQueue[T]) Len() int {
+	q.l.Lock()
+	defer q.l.Unlock()
+	return len(q.queue)
+}
+
+// 큐를 종료한다. 이후 추가되는 아이템은 무시한다.
+// 대기중인 아이템은 Get으로 꺼낼 수 있다.
+func (q *WorkQueue[T]) ShutDown() {
+	q.l.Lock()
+	defer q.l.Unlock()
+
+	q.shuttingDown = true
+	q.broadcast()
+}
+
+// 큐를 종료하고, 처리중인 아이템이 모두 Done 될 때까지 대기한다.
+// 대기중인 아이템도 모두 꺼내서 처리해야 반환되므로, 워커가 Get을 계속 호출해야 한다.
+func (q *WorkQueue[T]) ShutDownWithDrain() {
+	q.l.Lock()
+	defer q.l.Unlock()
+
+	q.shuttingDown = true
+	q.broadcast()
+
+	for len(q.queue) > 0 || len(q.processing) > 0 {
+		changed := q.changed()
+		q.l.Unlock()
+		<-changed
+		q.l.Lock()
+	}
+}
+
+// 큐가 종료되었나?
+func (q *WorkQueue[T]) ShuttingDown() bool {
+	q.l.Lock()
+	defer q.l.Unlock()
+	return q.shuttingDown
+}
+
+// 상태 변경 알림 채널을 반환한다. 락을 잡은 상태에서 호출
+func (q *WorkQueue[T]) changed() <-chan struct{} {
+	if q.notify == nil {
+		q.notify = make(chan struct{})
+	}
+	return q.notify
+}
+
+// 상태 변경을 기다리는 고루틴을 모두 깨운다. 락을 잡은 상태에서 호출
+func (q *WorkQueue[T]) broadcast() {
+	if q.notify != nil {
+		close(q.notify)
+		q.notify = nil
+	}
+}
@@ -0,0 +1,154 @@
+package pqueue
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 대기중인 아이템을 다시 추가하면 한번만 처리된다
+func TestWorkQueueShouldDeduplicateQueuedItems(t *testing.T) {
+	assert := assert.New(t)
+	q := NewWorkQueue[string]()
+
+	q.Add("a")
+	q.Add("b")
+	q.Add("a")
+	assert.Equal(2, q.Len())
+
+	ctx := context.Background()
+	for _, expected := range []string{"a", "b"} {
+		item, err := q.Get(ctx)
+		assert.NoError(err)
+		assert.Equal(expected, item)
+		q.Done(item)
+	}
+	assert.Zero(q.Len())
+}
+
+// 처리중인 아이템을 다시 추가하면 Done 호출 후 다시 큐에 추가된다
+func TestWorkQueueShouldRequeueItemAddedWhileProcessing(t *testing.T) {
+	assert := assert.New(t)
+	q := NewWorkQueue[string]()
+	ctx := context.Background()
+
+	q.Add("a")
+	item, err := q.Get(ctx)
+	assert.NoError(err)
+
+	// 처리중: 바로 큐에 추가되지 않는다
+	q.Add("a")
+	q.Add("a")
+	assert.Zero(q.Len())
+
+	// 처리 완료: 한번만 다시 추가된다
+	q.Done(item)
+	assert.Equal(1, q.Len())
+
+	item, err = q.Get(ctx)
+	assert.NoError(err)
+	assert.Equal("a", item)
+	q.Done(item)
+	assert.Zero(q.Len())
+}
+
+// 같은 아이템이 동시에 두 워커에서 처리되지 않는다
+func TestWorkQueueShouldNotProcessSameItemConcurrently(t *testing.T) {
+	assert := assert.New(t)
+	q := NewWorkQueue[int]()
+
+	workers, keys := runtime.NumCPU()*2, 10
+	inflight := make([]int32, keys)
+	var processed int64
+
+	wg := sync.WaitGroup{}
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for {
+				item, err := q.Get(context.Background())
+				if err != nil {
+					return
+				}
+				assert.Equal(int32(1), atomic.AddInt32(&inflight[item], 1))
+				time.Sleep(time.Microsecond * 100)
+				atomic.AddInt32(&inflight[item], -1)
+				atomic.AddInt64(&processed, 1)
+				q.Done(item)
+			}
+		}()
+	}
+
+	for i := 0; i < 1000; i++ {
+		q.Add(i % keys)
+	}
+	q.ShutDownWithDrain()
+	wg.Wait()
+
+	assert.Zero(q.Len())
+	t.Logf("added: 1000, processed: %d", processed)
+}
+
+// ShutDown 후에는 추가한 아이템이 무시되고, 남은 아이템을 꺼내면 ErrShutDown
+func TestWorkQueueShutDownShouldIgnoreNewItems(t *testing.T) {
+	assert := assert.New(t)
+	q := NewWorkQueue[int]()
+	ctx := context.Background()
+
+	q.Add(1)
+	q.ShutDown()
+	q.Add(2)
+	assert.True(q.ShuttingDown())
+	assert.Equal(1, q.Len())
+
+	item, err := q.Get(ctx)
+	assert.NoError(err)
+	assert.Equal(1, item)
+	q.Done(item)
+
+	_, err = q.Get(ctx)
+	assert.ErrorIs(err, ErrShutDown)
+
+	// ctx 종료
+	q = NewWorkQueue[int]()
+	cctx, cancel := context.WithTimeout(ctx, time.Millisecond*10)
+	defer cancel()
+	_, err = q.Get(cctx)
+	assert.ErrorIs(err, context.DeadlineExceeded)
+}
+
+// ShutDownWithDrain은 처리중인 아이템이 모두 Done 될 때까지 대기한다
+func TestWorkQueueShutDownWithDrainShouldWaitForDone(t *testing.T) {
+	assert := assert.New(t)
+	q := NewWorkQueue[int]()
+	ctx := context.Background()
+
+	q.Add(1)
+	item, err := q.Get(ctx)
+	assert.NoError(err)
+
+	drained := make(chan struct{})
+	go func() {
+		q.ShutDownWithDrain()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		assert.Fail("drained before Done")
+	case <-time.After(time.Millisecond * 20):
+	}
+
+	q.Done(item)
+	select {
+	case <-drained:
+	case <-time.After(time.Second):
+		assert.Fail("drain timeout")
+	}
+}
@@ -0,0 +1,74 @@
+package queue
+
+import "context"
+
+// 반복자(iterator)
+//
+// go 1.23 표준 라이브러리의 iter.Seq, iter.Seq2와 같은 형태이다. yield가 false를 반환하면 반복을 멈춘다.
+// go 1.20 에서는 함수를 직접 호출하고,
+//
+//	pc.All(ctx)(func(item Item) bool {
+//		fmt.Println(item)
+//		return true // false: 중단(break)
+//	})
+//
+// go 1.23 이상(range-over-func)에서는 코드 변경 없이 range 구문으로 사용할 수 있다.
+// 필요하면 iter.Seq[V](seq) 처럼 변환하거나, 타입을 iter.Seq의 alias로 바꾼다.
+//
+//	for item := range pc.All(ctx) {
+//		fmt.Println(item)
+//	}
+type Seq[V any] func(yield func(V) bool)
+
+// 두 개의 값(예: index, item)을 반환하는 반복자(iter.Seq2와 같은 형태)
+type Seq2[K, V any] func(yield func(K, V) bool)
+
+// 큐에서 아이템을 꺼내면서(소비) 반복한다.
+// 큐가 닫히고 남은 아이템을 모두 꺼내거나, ctx가 종료되면 반복을 멈춘다.
+// 중단(yield가 false 반환)한 경우 마지막으로 전달한 아이템은 이미 큐에서 꺼낸 상태이다.
+func All[T any](ctx context.Context, q BlockingQueue[T]) Seq[T] {
+	return func(yield func(T) bool) {
+		for {
+			item, err := q.Take(ctx)
+			if err != nil {
+				return
+			}
+			if !yield(item) {
+				return
+			}
+		}
+	}
+}
+
+// slice의 아이템을 순서대로 반복한다.
+func Values[V any](items []V) Seq[V] {
+	return func(yield func(V) bool) {
+		for _, item := range items {
+			if !yield(item) {
+				return
+			}
+		}
+	}
+}
+
+// 반복자의 모든 값을 slice로 모은다.
+func Collect[V any](seq Seq[V]) []V {
+	values := []V{}
+	seq(func(v V) bool {
+		values = append(values, v)
+		return true
+	})
+	return values
+}
+
+// 반복자에 순번(0부터)을 붙인다.
+func Enumerate[V any](seq Seq[V]) Seq2[int, V] {
+	return func(yield func(int, V) bool) {
+		i := 0
+		seq(func(v V) bool {
+			ok := yield(i, v)
+			i++
+			return ok
+		})
+	}
+}
@@ -0,0 +1,59 @@
+// 큐 공통 인터페이스
+//
+// ex8의 Channel, pqueue의 PriorityChannel, DelayingQueue 처럼 메소드 이름과 시그니처가 제각각인
+// 큐 구현을 같은 방식으로 사용하기 위한 인터페이스와 에러를 정의한다.
+// 구현이 따라야 할 규칙은 queuetest 패키지의 conformance 테스트로 검사한다.
+package queue
+
+import (
+	"context"
+	"errors"
+)
+
+// 큐 공통 에러
+// 구현은 이 에러를 그대로 반환하거나, errors.Is로 비교할 수 있도록 감싸서(%w) 반환한다.
+var (
+	ErrClosed = errors.New("queue closed")   // 닫힌 큐
+	ErrFull   = errors.New("queue is full")  // 큐 버퍼 가득참
+	ErrEmpty  = errors.New("queue is empty") // 큐 버퍼 비어있음
+)
+
+// 아이템을 추가하는 큐
+type Pusher[T any] interface {
+	// 아이템을 추가한다(대기하지 않음)
+	// 닫혔으면 ErrClosed, 가득찼으면 ErrFull
+	Offer(item T) error
+}
+
+// 아이템을 꺼내는 큐
+type Popper[T any] interface {
+	// 아이템을 꺼낸다(대기하지 않음)
+	// 비어있으면 ErrEmpty, 닫히고 비어있으면 ErrClosed
+	Poll() (T, error)
+}
+
+// 대기하지 않는 큐
+//
+// 규칙
+//   - Close 후에는 추가할 수 없지만, 남은 아이템은 꺼낼 수 있다(Go 채널과 같음).
+//   - Close는 여러번 호출해도 안전하다.
+//   - 모든 메소드는 concurrent-safe 하다.
+type Queue[T any] interface {
+	Pusher[T]
+	Popper[T]
+	// 아이템 수
+	Len() int
+	// 큐를 닫는다.
+	Close()
+}
+
+// 대기하는 큐
+type BlockingQueue[T any] interface {
+	Queue[T]
+	// 아이템을 추가한다(자리가 날 때까지 대기)
+	// ctx가 종료되면 ctx.Err(), 닫혔으면 ErrClosed
+	Put(ctx context.Context, item T) error
+	// 아이템을 꺼낸다(아이템이 있을때 까지 대기)
+	// ctx가 종료되면 ctx.Err(), 닫히고 비어있으면 ErrClosed. 대기중에 닫혀도 깨어난다.
+	Take(ctx context.Context) (T, error)
+}
@@ -0,0 +1,361 @@
+// 큐 공통 인터페이스(queue.Queue, queue.BlockingQueue)의 conformance 테스트
+//
+// 구현 패키지의 테스트에서 다음과 같이 실행한다.
+//
+//	func TestChannelConformance(t *testing.T) {
+//		queuetest.Run(t, queuetest.Config[int]{
+//			New:   func(cap int) queue.Queue[int] { return NewChannel[int](cap) },
+//			Item:  func(i int) int { return i },
+//			Index: func(item int) int { return item },
+//		})
+//	}
+package queuetest
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gostudy/pkg/queue"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 대기 여부를 판단하는 시간
+const wait = time.Millisecond * 20
+
+// 테스트할 큐 설정
+type Config[T any] struct {
+	// cap 크기의 빈 큐를 생성한다(Unbounded면 cap은 무시해도 된다)
+	New func(cap int) queue.Queue[T]
+	// i번째 테스트 아이템을 생성한다
+	Item func(i int) T
+	// 아이템의 번호(Item의 i)를 반환한다
+	Index func(item T) int
+	// 꺼내는 순서(nil: 입력 순서(FIFO))
+	// less(a, b)는 a가 b보다 먼저 꺼내져야 하면 true, 같은 순서의 아이템은 입력 순서로 꺼낸다.
+	Less func(a, b T) bool
+	// 크기 제한이 없는 큐(가득찼을 때의 검사를 생략)
+	Unbounded bool
+}
+
+// 큐가 공통 인터페이스의 규칙을 따르는지 검사한다.
+// 큐가 queue.BlockingQueue를 구현하면 대기하는 연산도 검사한다.
+func Run[T any](t *testing.T, cfg Config[T]) {
+	t.Run("Empty", cfg.testEmpty)
+	t.Run("Order", cfg.testOrder)
+	t.Run("Full", cfg.testFull)
+	t.Run("Close", cfg.testClose)
+	t.Run("Concurrent", cfg.testConcurrent)
+
+	if _, ok := cfg.New(1).(queue.BlockingQueue[T]); !ok {
+		return
+	}
+	t.Run("TakeShouldWait", cfg.testTakeWait)
+	t.Run("TakeShouldWakeOnClose", cfg.testTakeClose)
+	t.Run("TakeShouldStopOnContext", cfg.testTakeContext)
+	t.Run("PutShouldWait", cfg.testPutWait)
+	t.Run("PutShouldStopOnContext", cfg.testPutContext)
+	t.Run("All", cfg.testAll)
+}
+
+// 테스트가 끝나면 닫히는 큐를 생성한다.
+func (cfg Config[T]) new(t *testing.T, cap int) queue.Queue[T] {
+	q := cfg.New(cap)
+	t.Cleanup(q.Close)
+	return q
+}
+
+// 테스트가 끝나면 닫히는 대기하는 큐를 생성한다.
+func (cfg Config[T]) newBlocking(t *testing.T, cap int) queue.BlockingQueue[T] {
+	return cfg.new(t, cap).(queue.BlockingQueue[T])
+}
+
+// 비어있는 큐에서 꺼내면 ErrEmpty
+func (cfg Config[T]) testEmpty(t *testing.T) {
+	assert := assert.New(t)
+
+	q := cfg.new(t, 10)
+	assert.Equal(0, q.Len())
+	_, err := q.Poll()
+	assert.ErrorIs(err, queue.ErrEmpty)
+}
+
+// 추가한 아이템을 정해진 순서로 모두 꺼낸다
+func (cfg Config[T]) testOrder(t *testing.T) {
+	assert := assert.New(t)
+
+	const n = 10
+	q := cfg.new(t, n)
+	items := make([]T, n)
+	for i := range items {
+		items[i] = cfg.Item(i)
+		assert.NoError(q.Offer(items[i]))
+	}
+	assert.Equal(n, q.Len())
+
+	if cfg.Less != nil {
+		sort.SliceStable(items, func(i, j int) bool { return cfg.Less(items[i], items[j]) })
+	}
+	for _, expected := range items {
+		item, err := q.Poll()
+		assert.NoError(err)
+		assert.Equal(cfg.Index(expected), cfg.Index(item))
+	}
+	assert.Equal(0, q.Len())
+
+	_, err := q.Poll()
+	assert.ErrorIs(err, queue.ErrEmpty)
+}
+
+// 가득찬 큐에 추가하면 ErrFull
+func (cfg Config[T]) testFull(t *testing.T) {
+	if cfg.Unbounded {
+		t.Skip("unbounded queue")
+	}
+	assert := assert.New(t)
+
+	q := cfg.new(t, 2)
+	assert.NoError(q.Offer(cfg.Item(0)))
+	assert.NoError(q.Offer(cfg.Item(1)))
+	assert.ErrorIs(q.Offer(cfg.Item(2)), queue.ErrFull)
+	assert.Equal(2, q.Len())
+
+	// 꺼내면 다시 추가할 수 있다
+	_, err := q.Poll()
+	assert.NoError(err)
+	assert.NoError(q.Offer(cfg.Item(2)))
+}
+
+// 닫힌 큐에는 추가할 수 없지만 남은 아이템은 꺼낼 수 있고, 모두 꺼내면 ErrClosed
+func (cfg Config[T]) testClose(t *testing.T) {
+	assert := assert.New(t)
+
+	q := cfg.new(t, 10)
+	assert.NoError(q.Offer(cfg.Item(0)))
+	assert.NoError(q.Offer(cfg.Item(1)))
+
+	q.Close()
+	q.Close() // 여러번 호출해도 안전
+	assert.ErrorIs(q.Offer(cfg.Item(2)), queue.ErrClosed)
+	assert.Equal(2, q.Len())
+
+	var drained []int
+	for i := 0; i < 2; i++ {
+		item, err := q.Poll()
+		assert.NoError(err)
+		drained = append(drained, cfg.Index(item))
+	}
+	assert.ElementsMatch([]int{0, 1}, drained)
+
+	_, err := q.Poll()
+	assert.ErrorIs(err, queue.ErrClosed)
+}
+
+// 여러 고루틴이 동시에 추가하고 꺼내도 모든 아이템을 정확히 한번 꺼낸다
+func (cfg Config[T]) testConcurrent(t *testing.T) {
+	assert := assert.New(t)
+
+	const producers, consumers, n = 4, 4, 1000
+	q := cfg.new(t, 64)
+
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < n; {
+				err := q.Offer(cfg.Item(p*n + i))
+				if errors.Is(err, queue.ErrFull) {
+					runtime.Gosched()
+					continue
+				}
+				assert.NoError(err)
+				i++
+			}
+		}(p)
+	}
+
+	seen := make([]int32, producers*n)
+	var popped int64
+	for c := 0; c < consumers; c++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for atomic.LoadInt64(&popped) < producers*n {
+				item, err := q.Poll()
+				if err != nil {
+					assert.ErrorIs(err, queue.ErrEmpty)
+					runtime.Gosched()
+					continue
+				}
+				atomic.AddInt32(&seen[cfg.Index(item)], 1)
+				atomic.AddInt64(&popped, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i := range seen {
+		if !assert.Equal(int32(1), seen[i], "item %d", i) {
+			break
+		}
+	}
+	assert.Equal(0, q.Len())
+}
+
+// Take는 아이템이 추가될 때까지 대기한다
+func (cfg Config[T]) testTakeWait(t *testing.T) {
+	assert := assert.New(t)
+
+	q := cfg.newBlocking(t, 10)
+	taken := make(chan T, 1)
+	go func() {
+		item, err := q.Take(context.Background())
+		assert.NoError(err)
+		taken <- item
+	}()
+
+	select {
+	case <-taken:
+		t.Fatal("take should wait while the queue is empty")
+	case <-time.After(wait):
+	}
+
+	assert.NoError(q.Offer(cfg.Item(7)))
+	select {
+	case item := <-taken:
+		assert.Equal(7, cfg.Index(item))
+	case <-time.After(time.Second):
+		t.Fatal("take should return the offered item")
+	}
+}
+
+// 대기중인 Take는 큐가 닫히면 ErrClosed로 깨어난다
+func (cfg Config[T]) testTakeClose(t *testing.T) {
+	assert := assert.New(t)
+
+	q := cfg.newBlocking(t, 10)
+	errs := make(chan error, 1)
+	go func() {
+		_, err := q.Take(context.Background())
+		errs <- err
+	}()
+
+	time.Sleep(wait)
+	q.Close()
+	select {
+	case err := <-errs:
+		assert.ErrorIs(err, queue.ErrClosed)
+	case <-time.After(time.Second):
+		t.Fatal("take should wake up when the queue is closed")
+	}
+}
+
+// Take는 ctx가 종료되면 ctx.Err()를 반환한다
+func (cfg Config[T]) testTakeContext(t *testing.T) {
+	assert := assert.New(t)
+
+	q := cfg.newBlocking(t, 10)
+	ctx, cancel := context.WithTimeout(context.Background(), wait)
+	defer cancel()
+	_, err := q.Take(ctx)
+	assert.ErrorIs(err, context.DeadlineExceeded)
+}
+
+// 가득찬 큐에서 Put은 자리가 날 때까지 대기한다
+func (cfg Config[T]) testPutWait(t *testing.T) {
+	if cfg.Unbounded {
+		t.Skip("unbounded queue")
+	}
+	assert := assert.New(t)
+
+	q := cfg.newBlocking(t, 1)
+	assert.NoError(q.Offer(cfg.Item(0)))
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- q.Put(context.Background(), cfg.Item(1))
+	}()
+
+	select {
+	case <-errs:
+		t.Fatal("put should wait while the queue is full")
+	case <-time.After(wait):
+	}
+
+	item, err := q.Poll()
+	assert.NoError(err)
+	assert.Equal(0, cfg.Index(item))
+	select {
+	case err := <-errs:
+		assert.NoError(err)
+	case <-time.After(time.Second):
+		t.Fatal("put should add the item when the queue has room")
+	}
+	assert.Equal(1, q.Len())
+}
+
+// Put은 ctx가 종료되면 추가하지 않고 ctx.Err()를 반환한다. 닫힌 큐에는 ErrClosed
+func (cfg Config[T]) testPutContext(t *testing.T) {
+	assert := assert.New(t)
+
+	q := cfg.newBlocking(t, 1)
+	if !cfg.Unbounded {
+		assert.NoError(q.Offer(cfg.Item(0)))
+
+		ctx, cancel := context.WithTimeout(context.Background(), wait)
+		defer cancel()
+		assert.ErrorIs(q.Put(ctx, cfg.Item(1)), context.DeadlineExceeded)
+		assert.Equal(1, q.Len())
+	}
+
+	q.Close()
+	assert.ErrorIs(q.Put(context.Background(), cfg.Item(2)), queue.ErrClosed)
+}
+
+// queue.All은 닫힐 때까지 아이템을 꺼내면서 반복하고, 중단하거나 ctx가 종료되면 멈춘다
+func (cfg Config[T]) testAll(t *testing.T) {
+	assert := assert.New(t)
+
+	q := cfg.newBlocking(t, 10)
+	items := make([]T, 5)
+	for i := range items {
+		items[i] = cfg.Item(i)
+		assert.NoError(q.Offer(items[i]))
+	}
+	if cfg.Less != nil {
+		sort.SliceStable(items, func(i, j int) bool { return cfg.Less(items[i], items[j]) })
+	}
+
+	// 중단하면 그때까지 꺼낸 아이템만 소비된다
+	var taken []int
+	queue.All[T](context.Background(), q)(func(item T) bool {
+		taken = append(taken, cfg.Index(item))
+		return len(taken) < 2
+	})
+	assert.Equal([]int{cfg.Index(items[0]), cfg.Index(items[1])}, taken)
+	assert.Equal(3, q.Len())
+
+	// 비어있으면 ctx가 종료될 때까지 대기한다
+	assert.NoError(q.Offer(cfg.Item(5)))
+	ctx, cancel := context.WithTimeout(context.Background(), wait)
+	defer cancel()
+	assert.Len(queue.Collect(queue.All[T](ctx, q)), 4)
+	assert.ErrorIs(ctx.Err(), context.DeadlineExceeded)
+
+	// 닫히면 남은 아이템을 모두 꺼낸 후 멈춘다
+	assert.NoError(q.Offer(cfg.Item(6)))
+	q.Close()
+	taken = nil
+	queue.All[T](context.Background(), q)(func(item T) bool {
+		taken = append(taken, cfg.Index(item))
+		return true
+	})
+	assert.Equal([]int{6}, taken)
+}
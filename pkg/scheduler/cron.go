@@ -0,0 +1,393 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"gostudy/pkg/clock"
+	"gostudy/pkg/pqueue"
+)
+
+// 종료된 Cron
+var ErrStopped = errors.New("scheduler is stopped")
+
+// 이전 실행이 끝나기 전에 다음 실행 시각이 되었을 때의 처리 정책
+type Overlap int
+
+const (
+	OverlapSkip    Overlap = iota // 이번 실행을 건너뛴다(기본값)
+	OverlapQueue                  // 이전 실행이 끝난 후에 이어서 실행한다(밀린 실행을 Backlog 개 까지 순서대로 실행, 넘치면 건너뜀)
+	OverlapReplace                // 이전 실행의 ctx를 취소하고 새로 실행한다
+)
+
+func (o Overlap) String() string {
+	switch o {
+	case OverlapSkip:
+		return "skip"
+	case OverlapQueue:
+		return "queue"
+	case OverlapReplace:
+		return "replace"
+	}
+	return fmt.Sprintf("Overlap(%d)", int(o))
+}
+
+// 작업 ID
+type JobID uint64
+
+// 실행할 작업. ctx는 Replace 정책으로 교체되거나 Cron이 종료되면 취소된다.
+type Job func(ctx context.Context)
+
+// Cron 설정
+type CronConfig struct {
+	Workers  int            // 동시에 실행하는 작업 수(기본값 runtime.NumCPU())
+	Backlog  int            // 워커를 기다리는 실행의 최대 수(기본값 1024), 넘치면 스케줄 고루틴이 대기. 작업별 밀린 실행(OverlapQueue)의 최대 수
+	Clock    clock.Clock    // 시계(기본값 clock.Real, 테스트에서 clock.Fake 사용)
+	Location *time.Location // cron 표현식의 시간대(기본값 time.Local)
+}
+
+// cron 처럼 스케줄에 따라 작업을 실행하는 스케줄러
+//
+// 작업마다 다음 실행 시각을 지연 큐(pqueue.DelayingQueue)에 넣어두고, 스케줄 고루틴이 준비된 작업을 꺼내
+// 다음 실행 시각을 다시 예약한 뒤 워커 풀에 보낸다. 워커 풀은 예정 시각 순으로 실행한다.
+// 스케줄 고루틴이 밀려 지나간 실행 시각은 한 번만 실행하고 건너뛴다(catch-up 하지 않음).
+// 사용 후 반드시 Stop을 호출한다. 모든 메소드는 concurrent-safe 하다.
+type Cron struct {
+	l       sync.Mutex
+	clock   clock.Clock
+	loc     *time.Location
+	backlog int                                          // 작업별 밀린 실행(OverlapQueue)의 최대 수
+	timers  *pqueue.DelayingQueue[cronFire]              // 다음 실행 시각을 기다리는 작업
+	runs    *pqueue.PriorityChannel[*cronRun, time.Time] // 워커를 기다리는 실행(예정 시각 순)
+	entries map[JobID]*cronEntry                         // 등록된 작업
+	lastID  JobID
+	ctx     context.Context // 종료시 취소(모든 실행의 ctx의 부모)
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	stopped bool
+}
+
+// 등록된 작업
+type cronEntry struct {
+	id       JobID
+	schedule Schedule
+	overlap  Overlap
+	job      Job
+
+	next, prev time.Time
+	active     int                // 워커를 기다리거나 실행중인 실행 수
+	pending    []time.Time        // OverlapQueue: 이전 실행이 끝나기를 기다리는 실행의 예정 시각
+	cancel     context.CancelFunc // 가장 최근 실행의 취소(OverlapReplace)
+	runs       int
+	skipped    int
+	panics     int
+	removed    bool
+}
+
+// 실행 시각이 된 작업(지연 큐 아이템)
+type cronFire struct {
+	entry *cronEntry
+	at    time.Time
+}
+
+// 한 번의 실행
+type cronRun struct {
+	entry  *cronEntry
+	at     time.Time
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// 작업 상태
+type Entry struct {
+	ID      JobID
+	Overlap Overlap
+	Next    time.Time // 다음 실행 예정 시각(zero: 더 이상 실행하지 않음)
+	Prev    time.Time // 마지막 실행 예정 시각
+	Active  int       // 워커를 기다리거나 실행중인 실행 수
+	Pending int       // 이전 실행이 끝나기를 기다리는 실행 수(OverlapQueue)
+	Runs    int       // 실행한 횟수
+	Skipped int       // 건너뛴 횟수(OverlapSkip, Backlog를 넘친 OverlapQueue)
+	Panics  int       // 실행 중에 panic이 발생한 횟수
+}
+
+// Cron 생성(스케줄 고루틴, 워커 시작)
+func NewCron(cfg CronConfig) *Cron {
+	if cfg.Workers <= 0 {
+		cfg.Workers = runtime.NumCPU()
+	}
+	if cfg.Backlog <= 0 {
+		cfg.Backlog = 1024
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = clock.Real
+	}
+	if cfg.Location == nil {
+		cfg.Location = time.Local
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &Cron{
+		clock:   cfg.Clock,
+		loc:     cfg.Location,
+		backlog: cfg.Backlog,
+		timers:  pqueue.NewDelayingQueueWithClock[cronFire](cfg.Clock),
+		runs: pqueue.NewChannelFunc[*cronRun](cfg.Backlog, func(a, b time.Time) bool {
+			return a.Before(b)
+		}),
+		entries: map[JobID]*cronEntry{},
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+
+	c.wg.Add(cfg.Workers + 1)
+	go c.scheduleLoop()
+	for i := 0; i < cfg.Workers; i++ {
+		go c.worker()
+	}
+	return c
+}
+
+// 스케줄 표현식(Parse 참고)에 따라 작업을 실행한다.
+// 잘못된 표현식이면 ErrInvalidSpec
+func (c *Cron) Add(spec string, overlap Overlap, job Job) (JobID, error) {
+	schedule, err := ParseInLocation(spec, c.loc)
+	if err != nil {
+		return 0, err
+	}
+	return c.Schedule(schedule, overlap, job)
+}
+
+// 스케줄에 따라 작업을 실행한다.
+// 종료되었으면 ErrStopped, 현재 이후로 실행 시각이 없으면(지난 OnceSchedule 등) ErrInvalidSpec
+func (c *Cron) Schedule(schedule Schedule, overlap Overlap, job Job) (JobID, error) {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	if c.stopped {
+		return 0, ErrStopped
+	}
+	now := c.clock.Now()
+	next := schedule.Next(now)
+	if next.IsZero() {
+		return 0, fmt.Errorf("%w: no run after %v", ErrInvalidSpec, now)
+	}
+
+	c.lastID++
+	e := &cronEntry{id: c.lastID, schedule: schedule, overlap: overlap, job: job, next: next}
+	c.entries[e.id] = e
+	c.timers.AddAt(cronFire{entry: e, at: next}, next)
+	return e.id, nil
+}
+
+// 작업을 삭제한다. 이미 시작한 실행은 취소하지 않는다.
+// 등록된 작업이 아니면 false
+func (c *Cron) Remove(id JobID) bool {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	e, ok := c.entries[id]
+	if !ok {
+		return false
+	}
+	e.removed = true
+	e.pending = nil
+	delete(c.entries, id)
+	return true
+}
+
+// 작업 상태
+// 등록된 작업이 아니면(삭제되었거나 실행 시각이 더 없는 작업 포함) false
+func (c *Cron) Entry(id JobID) (Entry, bool) {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	e, ok := c.entries[id]
+	if !ok {
+		return Entry{}, false
+	}
+	return e.status(), true
+}
+
+// 등록된 모든 작업의 상태(다음 실행 시각 순)
+func (c *Cron) Entries() []Entry {
+	c.l.Lock()
+	entries := make([]Entry, 0, len(c.entries))
+	for _, e := range c.entries {
+		entries = append(entries, e.status())
+	}
+	c.l.Unlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].Next.Equal(entries[j].Next) {
+			return entries[i].Next.Before(entries[j].Next)
+		}
+		return entries[i].ID < entries[j].ID
+	})
+	return entries
+}
+
+// 스케줄러를 종료한다. 더 이상 실행하지 않고, 워커를 기다리던 실행은 버리며,
+// 실행중인 작업의 ctx를 취소하고 끝날 때까지 기다린다. 여러번 호출해도 안전하다.
+func (c *Cron) Stop() {
+	c.l.Lock()
+	c.stopped = true
+	c.l.Unlock()
+
+	c.cancel()
+	c.timers.ShutDown()
+	c.runs.CloseNow() // WAL을 사용하지 않으므로 에러 없음
+	c.wg.Wait()
+}
+
+// 작업 상태. 락을 잡은 상태에서 호출
+func (e *cronEntry) status() Entry {
+	return Entry{
+		ID:      e.id,
+		Overlap: e.overlap,
+		Next:    e.next,
+		Prev:    e.prev,
+		Active:  e.active,
+		Pending: len(e.pending),
+		Runs:    e.runs,
+		Skipped: e.skipped,
+		Panics:  e.panics,
+	}
+}
+
+// 스케줄 고루틴
+// 실행 시각이 된 작업의 다음 실행을 예약하고 워커 풀에 보낸다.
+func (c *Cron) scheduleLoop() {
+	defer c.wg.Done()
+
+	for {
+		fire, err := c.timers.Get(c.ctx)
+		if err != nil {
+			return
+		}
+		if run := c.fire(fire); run != nil {
+			c.dispatch(run)
+		}
+	}
+}
+
+// 다음 실행을 예약하고, 중복 정책에 따라 이번 실행을 만든다.
+func (c *Cron) fire(f cronFire) *cronRun {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	e := f.entry
+	if e.removed || c.stopped {
+		return nil
+	}
+
+	// 밀려서 다음 실행 시각도 지났으면 현재 이후로 건너뛴다
+	now := c.clock.Now()
+	next := e.schedule.Next(f.at)
+	if !next.IsZero() && !next.After(now) {
+		next = e.schedule.Next(now)
+	}
+	e.prev, e.next = f.at, next
+	if next.IsZero() {
+		delete(c.entries, e.id)
+	} else {
+		c.timers.AddAt(cronFire{entry: e, at: next}, next)
+	}
+
+	if e.active > 0 {
+		switch e.overlap {
+		case OverlapQueue:
+			// 오래 걸리는 작업의 밀린 실행이 끝없이 쌓이지 않게 Backlog 개 까지만 기다린다
+			if len(e.pending) < c.backlog {
+				e.pending = append(e.pending, f.at)
+			} else {
+				e.skipped++
+			}
+			return nil
+		case OverlapReplace:
+			e.cancel()
+		default:
+			e.skipped++
+			return nil
+		}
+	}
+	return c.start(e, f.at)
+}
+
+// 새 실행을 만든다. 락을 잡은 상태에서 호출
+func (c *Cron) start(e *cronEntry, at time.Time) *cronRun {
+	ctx, cancel := context.WithCancel(c.ctx)
+	e.active++
+	e.cancel = cancel
+	return &cronRun{entry: e, at: at, ctx: ctx, cancel: cancel}
+}
+
+// 실행을 워커 풀에 보낸다(대기열이 가득차면 대기)
+// 보내지 못하면(종료) 실행하지 않고 마치며, 이어서 실행하려던(OverlapQueue) 실행도 모두 마친다.
+func (c *Cron) dispatch(run *cronRun) {
+	err := c.runs.Put(c.ctx, pqueue.Item[*cronRun, time.Time]{Data: run, Priority: run.at})
+	if err != nil {
+		for run != nil {
+			run = c.finish(run)
+		}
+	}
+}
+
+// 워커: 실행을 꺼내 작업을 실행한다.
+func (c *Cron) worker() {
+	defer c.wg.Done()
+
+	for {
+		item, err := c.runs.Take(context.Background())
+		if err != nil {
+			return
+		}
+		// OverlapQueue로 밀린 실행은 같은 워커가 이어서 실행한다
+		for run := item.Data; run != nil; run = c.finish(run) {
+			// 시작 전에 교체(OverlapReplace)되었거나 종료되었으면 실행하지 않는다
+			if run.ctx.Err() != nil {
+				continue
+			}
+			c.run(run)
+		}
+	}
+}
+
+// 작업을 실행한다.
+// 작업에서 발생한 panic은 여기서 멈추고 횟수만 기록하므로, 워커와 다음 실행(finish)은 계속된다.
+func (c *Cron) run(run *cronRun) {
+	c.l.Lock()
+	run.entry.runs++
+	c.l.Unlock()
+
+	defer func() {
+		if r := recover(); r != nil {
+			c.l.Lock()
+			run.entry.panics++
+			c.l.Unlock()
+		}
+	}()
+	run.entry.job(run.ctx)
+}
+
+// 실행을 마치고, 이전 실행이 끝나기를 기다리던(OverlapQueue) 실행이 있으면 반환한다.
+func (c *Cron) finish(run *cronRun) *cronRun {
+	run.cancel()
+
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	e := run.entry
+	e.active--
+	if e.active > 0 || len(e.pending) == 0 || e.removed || c.stopped {
+		return nil
+	}
+	at := e.pending[0]
+	e.pending = e.pending[1:]
+	return c.start(e, at)
+}
@@ -0,0 +1,334 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 잘못된 스케줄 표현식(Parse)
+var ErrInvalidSpec = errors.New("invalid schedule spec")
+
+// 실행 스케줄
+type Schedule interface {
+	// t 이후(t 제외)의 다음 실행 시각, 더 이상 실행하지 않으면 zero time
+	Next(t time.Time) time.Time
+}
+
+// 일정한 간격으로 실행하는 스케줄(@every)
+type EverySchedule struct {
+	Every time.Duration
+}
+
+func (s EverySchedule) Next(t time.Time) time.Time {
+	return t.Add(s.Every)
+}
+
+// 한 번만 실행하는 스케줄(@at)
+type OnceSchedule struct {
+	At time.Time
+}
+
+func (s OnceSchedule) Next(t time.Time) time.Time {
+	if t.Before(s.At) {
+		return s.At
+	}
+	return time.Time{}
+}
+
+// cron 표현식 스케줄
+// 각 필드는 허용하는 값의 비트셋이다(비트 i가 1이면 값 i 허용).
+type SpecSchedule struct {
+	Second, Minute, Hour, Dom, Month, Dow uint64
+	Location                              *time.Location
+
+	domStar, dowStar bool // 일, 요일 필드가 *(?) 인가?
+}
+
+// 필드의 값 범위와 이름
+type bounds struct {
+	min, max int
+	names    map[string]int
+}
+
+var (
+	seconds = bounds{0, 59, nil}
+	minutes = bounds{0, 59, nil}
+	hours   = bounds{0, 23, nil}
+	doms    = bounds{1, 31, nil}
+	months  = bounds{1, 12, map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// 0, 7 모두 일요일
+	dows = bounds{0, 7, map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// 미리 정의된 스케줄(6 필드)
+var descriptors = map[string]string{
+	"@yearly":   "0 0 0 1 1 *",
+	"@annually": "0 0 0 1 1 *",
+	"@monthly":  "0 0 0 1 * *",
+	"@weekly":   "0 0 0 * * 0",
+	"@daily":    "0 0 0 * * *",
+	"@midnight": "0 0 0 * * *",
+	"@hourly":   "0 0 * * * *",
+}
+
+// 스케줄 표현식을 로컬 시간대로 해석한다(ParseInLocation)
+func Parse(spec string) (Schedule, error) {
+	return ParseInLocation(spec, time.Local)
+}
+
+// 스케줄 표현식을 해석한다. cron 표현식은 loc 시간대로 계산한다.
+//
+//	분 시 일 월 요일            5 필드(초는 0)
+//	초 분 시 일 월 요일         6 필드
+//	@yearly(@annually), @monthly, @weekly, @daily(@midnight), @hourly
+//	@every <duration>        일정한 간격(time.ParseDuration 형식, 예: @every 1h30m)
+//	@at <RFC3339 시각>         한 번만 실행(예: @at 2023-04-01T09:00:00+09:00)
+//
+// 필드는 *, ?(일, 요일에서 *와 같음), 값, a-b 범위, /n 간격(*/5, 1-30/2, 10/15), 콤마로 구분한 목록을 지원하고
+// 월, 요일은 영문 약어(JAN, MON 등)를 사용할 수 있다. 요일의 0, 7은 일요일이다.
+// 일, 요일이 모두 *가 아니면 둘 중 하나만 일치해도 실행한다(표준 cron과 같음).
+// "TZ=Asia/Seoul "로 시작하면 loc 대신 주어진 시간대를 사용한다.
+func ParseInLocation(spec string, loc *time.Location) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, fmt.Errorf("%w: empty spec", ErrInvalidSpec)
+	}
+
+	if strings.HasPrefix(spec, "TZ=") || strings.HasPrefix(spec, "CRON_TZ=") {
+		i := strings.IndexAny(spec, " \t")
+		if i < 0 {
+			return nil, fmt.Errorf("%w: missing fields after %q", ErrInvalidSpec, spec)
+		}
+		name := spec[strings.Index(spec, "=")+1 : i]
+		var err error
+		if loc, err = time.LoadLocation(name); err != nil {
+			return nil, fmt.Errorf("%w: time zone %q: %v", ErrInvalidSpec, name, err)
+		}
+		spec = strings.TrimSpace(spec[i:])
+	}
+
+	if strings.HasPrefix(spec, "@") {
+		return parseDescriptor(spec, loc)
+	}
+
+	fields := strings.Fields(spec)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return nil, fmt.Errorf("%w: expected 5 or 6 fields, found %d: %q", ErrInvalidSpec, len(fields), spec)
+	}
+
+	s := &SpecSchedule{Location: loc}
+	var err error
+	for i, f := range []struct {
+		field  *uint64
+		bounds bounds
+	}{
+		{&s.Second, seconds},
+		{&s.Minute, minutes},
+		{&s.Hour, hours},
+		{&s.Dom, doms},
+		{&s.Month, months},
+		{&s.Dow, dows},
+	} {
+		if *f.field, err = parseField(fields[i], f.bounds); err != nil {
+			return nil, fmt.Errorf("%w: %q: %v", ErrInvalidSpec, spec, err)
+		}
+	}
+	// 7(일요일) -> 0
+	if s.Dow&(1<<7) != 0 {
+		s.Dow = s.Dow&^(1<<7) | 1
+	}
+	s.domStar = isStar(fields[3])
+	s.dowStar = isStar(fields[5])
+	return s, nil
+}
+
+// @로 시작하는 표현식을 해석한다.
+func parseDescriptor(spec string, loc *time.Location) (Schedule, error) {
+	if expr, ok := descriptors[strings.ToLower(spec)]; ok {
+		return ParseInLocation(expr, loc)
+	}
+
+	name, arg, _ := strings.Cut(spec, " ")
+	arg = strings.TrimSpace(arg)
+	switch strings.ToLower(name) {
+	case "@every":
+		d, err := time.ParseDuration(arg)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q: %v", ErrInvalidSpec, spec, err)
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("%w: %q: interval must be positive", ErrInvalidSpec, spec)
+		}
+		return EverySchedule{Every: d}, nil
+	case "@at":
+		at, err := time.Parse(time.RFC3339, arg)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q: %v", ErrInvalidSpec, spec, err)
+		}
+		return OnceSchedule{At: at}, nil
+	}
+	return nil, fmt.Errorf("%w: unknown descriptor %q", ErrInvalidSpec, spec)
+}
+
+// 필드가 모든 값(*, ?)인가?
+func isStar(field string) bool {
+	return field == "*" || field == "?"
+}
+
+// 필드를 해석하여 허용하는 값의 비트셋을 반환한다.
+func parseField(field string, b bounds) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		expr, stepExpr, hasStep := strings.Cut(part, "/")
+
+		var start, end int
+		var err error
+		switch {
+		case expr == "*" || expr == "?":
+			start, end = b.min, b.max
+		case strings.Contains(expr, "-"):
+			lo, hi, _ := strings.Cut(expr, "-")
+			if start, err = parseValue(lo, b); err != nil {
+				return 0, err
+			}
+			if end, err = parseValue(hi, b); err != nil {
+				return 0, err
+			}
+		default:
+			if start, err = parseValue(expr, b); err != nil {
+				return 0, err
+			}
+			end = start
+			// 10/15: 10부터 최대값까지 15 간격
+			if hasStep {
+				end = b.max
+			}
+		}
+
+		step := 1
+		if hasStep {
+			if step, err = strconv.Atoi(stepExpr); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %q", part)
+			}
+		}
+		if start > end {
+			return 0, fmt.Errorf("invalid range %q", part)
+		}
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// 값(숫자 또는 이름)을 해석하고 범위를 검사한다.
+func parseValue(expr string, b bounds) (int, error) {
+	if v, ok := b.names[strings.ToLower(expr)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(expr)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", expr)
+	}
+	if v < b.min || v > b.max {
+		return 0, fmt.Errorf("value %d out of range [%d, %d]", v, b.min, b.max)
+	}
+	return v, nil
+}
+
+// 값이 허용되는가?
+func has(bits uint64, v int) bool {
+	return bits&(1<<uint(v)) != 0
+}
+
+// t 이후(t 제외)의 가장 빠른 실행 시각
+// 큰 단위(월)부터 일치하지 않으면 다음 값으로 넘기고(아래 단위는 초기화), 넘치면 처음부터 다시 검사한다.
+// 5년 안에 실행 시각이 없으면(예: 2월 30일) zero time
+func (s *SpecSchedule) Next(t time.Time) time.Time {
+	loc := s.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	origin := t.Location()
+	t = t.In(loc)
+
+	// 다음 초부터 검사
+	t = t.Add(time.Second - time.Duration(t.Nanosecond()))
+	limit := t.Year() + 5
+
+wrap:
+	if t.Year() > limit {
+		return time.Time{}
+	}
+
+	for !has(s.Month, int(t.Month())) {
+		t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		if t.Month() == time.January {
+			goto wrap
+		}
+	}
+	for !s.dayMatches(t) {
+		t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		if t.Day() == 1 {
+			goto wrap
+		}
+	}
+	for !has(s.Hour, t.Hour()) {
+		next := time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+		// 서머타임 전환으로 같은 시간이 반복되면 한 시간 뒤로
+		if !next.After(t) {
+			next = next.Add(time.Hour)
+		}
+		t = next
+		if t.Hour() == 0 {
+			goto wrap
+		}
+	}
+	// Truncate는 zero time 기준으로 자르므로, UTC 오프셋이 분 단위가 아닌 시간대(LMT 등)에서는
+	// 벽시계의 분, 초와 맞지 않는다. 따라서 시간대의 벽시계 기준으로 다음 값을 만든다.
+	for !has(s.Minute, t.Minute()) {
+		next := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute()+1, 0, 0, loc)
+		// 서머타임 전환으로 같은 시간이 반복되면 한 시간 뒤로
+		if !next.After(t) {
+			next = next.Add(time.Hour)
+		}
+		t = next
+		if t.Minute() == 0 {
+			goto wrap
+		}
+	}
+	for !has(s.Second, t.Second()) {
+		next := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second()+1, 0, loc)
+		if !next.After(t) {
+			next = next.Add(time.Hour)
+		}
+		t = next
+		if t.Second() == 0 {
+			goto wrap
+		}
+	}
+	return t.In(origin)
+}
+
+// 일, 요일이 일치하는가?
+// 둘 중 하나가 *이면 둘 다 일치해야 하고, 아니면 하나만 일치해도 된다.
+func (s *SpecSchedule) dayMatches(t time.Time) bool {
+	dom := has(s.Dom, t.Day())
+	dow := has(s.Dow, int(t.Weekday()))
+	if s.domStar || s.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
@@ -0,0 +1,146 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 2023-04-01(토) 00:00:00 UTC
+var epoch = time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)
+
+func parseTime(s string) time.Time {
+	t, err := time.Parse("2006-01-02 15:04:05", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// 주어진 시각 이후의 다음 실행 시각
+func TestParseNext(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		spec     string
+		from     string
+		expected []string
+	}{
+		// 5 필드: 초는 0
+		{"* * * * *", "2023-04-01 00:00:00", []string{"2023-04-01 00:01:00", "2023-04-01 00:02:00"}},
+		{"*/15 * * * *", "2023-04-01 00:07:30", []string{"2023-04-01 00:15:00", "2023-04-01 00:30:00", "2023-04-01 00:45:00", "2023-04-01 01:00:00"}},
+		{"30 9 * * MON-FRI", "2023-04-01 00:00:00", []string{"2023-04-03 09:30:00", "2023-04-04 09:30:00"}},
+		{"0 0 1,15 * *", "2023-04-01 00:00:00", []string{"2023-04-15 00:00:00", "2023-05-01 00:00:00"}},
+		{"0 12 * JAN,jul *", "2023-04-01 00:00:00", []string{"2023-07-01 12:00:00", "2023-07-02 12:00:00"}},
+		{"0 0 29 2 *", "2023-04-01 00:00:00", []string{"2024-02-29 00:00:00", "2028-02-29 00:00:00"}},
+		// 요일 7 = 일요일
+		{"0 0 * * 7", "2023-04-01 00:00:00", []string{"2023-04-02 00:00:00", "2023-04-09 00:00:00"}},
+		// 일, 요일이 모두 지정되면 OR
+		{"0 0 13 * FRI", "2023-04-01 00:00:00", []string{"2023-04-07 00:00:00", "2023-04-13 00:00:00", "2023-04-14 00:00:00"}},
+		// ?는 *와 같다
+		{"0 0 ? * 1", "2023-04-01 00:00:00", []string{"2023-04-03 00:00:00"}},
+		// 범위와 간격
+		{"0 8-18/5 * * *", "2023-04-01 00:00:00", []string{"2023-04-01 08:00:00", "2023-04-01 13:00:00", "2023-04-01 18:00:00", "2023-04-02 08:00:00"}},
+		{"50/5 * * * *", "2023-04-01 00:00:00", []string{"2023-04-01 00:50:00", "2023-04-01 00:55:00", "2023-04-01 01:50:00"}},
+		// 6 필드: 초 포함
+		{"*/20 * * * * *", "2023-04-01 00:00:00", []string{"2023-04-01 00:00:20", "2023-04-01 00:00:40", "2023-04-01 00:01:00"}},
+		{"59 59 23 31 12 *", "2023-04-01 00:00:00", []string{"2023-12-31 23:59:59", "2024-12-31 23:59:59"}},
+		// 정의된 스케줄
+		{"@hourly", "2023-04-01 00:00:00", []string{"2023-04-01 01:00:00"}},
+		{"@daily", "2023-04-01 10:00:00", []string{"2023-04-02 00:00:00"}},
+		{"@weekly", "2023-04-01 00:00:00", []string{"2023-04-02 00:00:00", "2023-04-09 00:00:00"}},
+		{"@monthly", "2023-04-01 00:00:00", []string{"2023-05-01 00:00:00"}},
+		{"@yearly", "2023-04-01 00:00:00", []string{"2024-01-01 00:00:00"}},
+		{"@every 90s", "2023-04-01 00:00:00", []string{"2023-04-01 00:01:30", "2023-04-01 00:03:00"}},
+		{"@at 2023-04-01T12:00:00Z", "2023-04-01 00:00:00", []string{"2023-04-01 12:00:00", "0001-01-01 00:00:00"}},
+		// 실행 시각이 없으면 zero time
+		{"0 0 30 2 *", "2023-04-01 00:00:00", []string{"0001-01-01 00:00:00"}},
+	}
+	for _, test := range tests {
+		s, err := ParseInLocation(test.spec, time.UTC)
+		if !assert.NoError(err, test.spec) {
+			continue
+		}
+		next := parseTime(test.from)
+		for _, expected := range test.expected {
+			next = s.Next(next)
+			assert.Equal(parseTime(expected), next.UTC(), test.spec)
+		}
+	}
+}
+
+// 시간대: loc 또는 TZ= 접두사
+func TestParseLocation(t *testing.T) {
+	assert := assert.New(t)
+
+	seoul, err := time.LoadLocation("Asia/Seoul")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	s, err := ParseInLocation("0 9 * * *", seoul)
+	assert.NoError(err)
+	assert.Equal(parseTime("2023-04-01 00:00:00"), s.Next(epoch.Add(-time.Hour)).UTC())
+
+	s, err = ParseInLocation("TZ=Asia/Seoul 0 9 * * *", time.UTC)
+	assert.NoError(err)
+	// epoch는 서울 09:00(실행 시각은 포함하지 않는다)
+	next := s.Next(epoch)
+	assert.Equal(parseTime("2023-04-02 00:00:00"), next)
+	// 입력 시각의 시간대로 반환한다
+	assert.Equal(time.UTC, next.Location())
+}
+
+// UTC 오프셋이 분 단위가 아닌 시간대(LMT)에서도 벽시계의 분, 초에 실행한다
+func TestParseLocationWithSecondsOffset(t *testing.T) {
+	assert := assert.New(t)
+
+	// 서울의 지방 평균시(LMT +8:27:52)
+	lmt := time.FixedZone("LMT", 8*3600+27*60+52)
+	for _, spec := range []string{"30 * * * *", "*/15 * * * * *"} {
+		s, err := ParseInLocation(spec, lmt)
+		assert.NoError(err)
+
+		at := epoch
+		for i := 0; i < 5; i++ {
+			at = s.Next(at)
+			wall := at.In(lmt)
+			assert.Zero(wall.Nanosecond(), spec)
+			if spec == "30 * * * *" {
+				assert.Equal(30, wall.Minute(), spec)
+				assert.Zero(wall.Second(), spec)
+			} else {
+				assert.Zero(wall.Second()%15, spec)
+			}
+		}
+	}
+}
+
+// 잘못된 표현식은 ErrInvalidSpec
+func TestParseInvalidSpec(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"* * * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"10-5 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+		"* * * FOO *",
+		"@every",
+		"@every -1s",
+		"@at tomorrow",
+		"@sometimes",
+		"TZ=Nowhere/Never * * * * *",
+	} {
+		_, err := Parse(spec)
+		assert.ErrorIs(err, ErrInvalidSpec, spec)
+	}
+}
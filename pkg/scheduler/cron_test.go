@@ -0,0 +1,340 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gostudy/pkg/clock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 가짜 시계를 사용하는 Cron
+func newFakeCron(t *testing.T, workers int) (*Cron, *clock.Fake) {
+	fake := clock.NewFake(epoch)
+	c := NewCron(CronConfig{Workers: workers, Clock: fake, Location: time.UTC})
+	t.Cleanup(c.Stop)
+	return c, fake
+}
+
+// 스케줄 고루틴이 at 시각의 실행을 처리할 때까지 기다린다
+func waitFired(t *testing.T, c *Cron, id JobID, at time.Time) Entry {
+	var e Entry
+	assert.Eventually(t, func() bool {
+		e, _ = c.Entry(id)
+		return !e.Prev.Before(at)
+	}, time.Second, time.Millisecond)
+	return e
+}
+
+// 채널에서 값을 기다린다(최대 1초)
+func receive[T any](t *testing.T, c <-chan T) (v T) {
+	select {
+	case v = <-c:
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+	}
+	return v
+}
+
+// 채널에 값이 없어야 한다
+func assertEmpty[T any](t *testing.T, c <-chan T) {
+	select {
+	case v := <-c:
+		t.Fatalf("unexpected %v", v)
+	case <-time.After(time.Millisecond * 10):
+	}
+}
+
+// cron 표현식의 실행 시각마다 실행한다
+func TestCronShouldRunOnSchedule(t *testing.T) {
+	assert := assert.New(t)
+	c, fake := newFakeCron(t, 1)
+
+	ran := make(chan time.Time, 10)
+	id, err := c.Add("*/5 * * * *", OverlapSkip, func(context.Context) { ran <- fake.Now() })
+	assert.NoError(err)
+
+	e, ok := c.Entry(id)
+	assert.True(ok)
+	assert.Equal(epoch.Add(time.Minute*5), e.Next)
+
+	fake.Set(epoch.Add(time.Minute*5 - time.Second))
+	assertEmpty(t, ran)
+
+	for i := 1; i <= 3; i++ {
+		at := epoch.Add(time.Minute * time.Duration(5*i))
+		fake.Set(at)
+		assert.Equal(at, receive(t, ran))
+		e = waitFired(t, c, id, at)
+		assert.Equal(at.Add(time.Minute*5), e.Next)
+	}
+	assert.Eventually(func() bool {
+		e, _ := c.Entry(id)
+		return e.Runs == 3
+	}, time.Second, time.Millisecond)
+}
+
+// 밀린 실행 시각은 한 번만 실행하고 현재 이후로 건너뛴다
+func TestCronShouldSkipMissedRuns(t *testing.T) {
+	assert := assert.New(t)
+	c, fake := newFakeCron(t, 1)
+
+	ran := make(chan time.Time, 10)
+	id, err := c.Add("@every 1m", OverlapSkip, func(context.Context) { ran <- fake.Now() })
+	assert.NoError(err)
+
+	fake.Advance(time.Minute*10 + time.Second)
+	receive(t, ran)
+	e := waitFired(t, c, id, epoch.Add(time.Minute))
+	assert.Equal(epoch.Add(time.Minute*11+time.Second), e.Next)
+	assertEmpty(t, ran)
+}
+
+// 한 번만 실행하는 스케줄은 실행 후 삭제된다
+func TestCronOneShot(t *testing.T) {
+	assert := assert.New(t)
+	c, fake := newFakeCron(t, 1)
+
+	ran := make(chan struct{}, 10)
+	id, err := c.Add("@at 2023-04-01T01:00:00Z", OverlapSkip, func(context.Context) { ran <- struct{}{} })
+	assert.NoError(err)
+	assert.Len(c.Entries(), 1)
+
+	fake.Advance(time.Hour)
+	receive(t, ran)
+	assert.Eventually(func() bool {
+		_, ok := c.Entry(id)
+		return !ok
+	}, time.Second, time.Millisecond)
+	fake.Advance(time.Hour)
+	assertEmpty(t, ran)
+
+	// 이미 지난 시각
+	_, err = c.Schedule(OnceSchedule{At: epoch}, OverlapSkip, func(context.Context) {})
+	assert.ErrorIs(err, ErrInvalidSpec)
+}
+
+// 실행 시각에 시작/종료를 알리고 release를 기다리는 작업
+type blockingJob struct {
+	started  chan int
+	finished chan int
+	release  chan struct{}
+	runs     int
+}
+
+func newBlockingJob() *blockingJob {
+	return &blockingJob{
+		started:  make(chan int, 10),
+		finished: make(chan int, 10),
+		release:  make(chan struct{}),
+	}
+}
+
+func (j *blockingJob) run(ctx context.Context) {
+	j.runs++
+	n := j.runs
+	j.started <- n
+	select {
+	case <-j.release:
+	case <-ctx.Done():
+	}
+	j.finished <- n
+}
+
+// OverlapSkip: 이전 실행이 끝나지 않았으면 건너뛴다
+func TestCronOverlapSkip(t *testing.T) {
+	assert := assert.New(t)
+	c, fake := newFakeCron(t, 2)
+
+	job := newBlockingJob()
+	id, err := c.Add("@every 1m", OverlapSkip, job.run)
+	assert.NoError(err)
+
+	fake.Advance(time.Minute)
+	assert.Equal(1, receive(t, job.started))
+
+	fake.Advance(time.Minute)
+	e := waitFired(t, c, id, epoch.Add(time.Minute*2))
+	assert.Equal(1, e.Skipped)
+	assertEmpty(t, job.started)
+
+	job.release <- struct{}{}
+	assert.Equal(1, receive(t, job.finished))
+
+	fake.Advance(time.Minute)
+	assert.Equal(2, receive(t, job.started))
+	job.release <- struct{}{}
+	assert.Equal(2, receive(t, job.finished))
+
+	e, _ = c.Entry(id)
+	assert.Equal(2, e.Runs)
+	assert.Equal(1, e.Skipped)
+}
+
+// OverlapQueue: 이전 실행이 끝나면 밀린 실행을 이어서 실행한다
+func TestCronOverlapQueue(t *testing.T) {
+	assert := assert.New(t)
+	c, fake := newFakeCron(t, 2)
+
+	job := newBlockingJob()
+	id, err := c.Add("@every 1m", OverlapQueue, job.run)
+	assert.NoError(err)
+
+	fake.Advance(time.Minute)
+	assert.Equal(1, receive(t, job.started))
+	fake.Advance(time.Minute)
+	waitFired(t, c, id, epoch.Add(time.Minute*2))
+	fake.Advance(time.Minute)
+	e := waitFired(t, c, id, epoch.Add(time.Minute*3))
+	assert.Equal(2, e.Pending)
+	assertEmpty(t, job.started)
+
+	// 한 번에 하나씩 순서대로 실행된다
+	for i := 1; i <= 3; i++ {
+		job.release <- struct{}{}
+		assert.Equal(i, receive(t, job.finished))
+		if i < 3 {
+			assert.Equal(i+1, receive(t, job.started))
+		}
+	}
+	assertEmpty(t, job.started)
+}
+
+// OverlapQueue: 밀린 실행은 Backlog 개 까지만 기다리고, 넘치면 건너뛴다
+func TestCronOverlapQueueShouldLimitPending(t *testing.T) {
+	assert := assert.New(t)
+	fake := clock.NewFake(epoch)
+	c := NewCron(CronConfig{Workers: 2, Backlog: 2, Clock: fake, Location: time.UTC})
+	t.Cleanup(c.Stop)
+
+	job := newBlockingJob()
+	id, err := c.Add("@every 1m", OverlapQueue, job.run)
+	assert.NoError(err)
+
+	fake.Advance(time.Minute)
+	assert.Equal(1, receive(t, job.started))
+	var e Entry
+	for i := 2; i <= 5; i++ {
+		fake.Advance(time.Minute)
+		e = waitFired(t, c, id, epoch.Add(time.Minute*time.Duration(i)))
+	}
+	assert.Equal(2, e.Pending)
+	assert.Equal(2, e.Skipped)
+
+	// 첫번째 실행과 밀린 두 실행만 실행된다
+	for i := 1; i <= 3; i++ {
+		job.release <- struct{}{}
+		assert.Equal(i, receive(t, job.finished))
+		if i < 3 {
+			assert.Equal(i+1, receive(t, job.started))
+		}
+	}
+	assertEmpty(t, job.started)
+}
+
+// OverlapReplace: 이전 실행을 취소하고 새로 실행한다
+func TestCronOverlapReplace(t *testing.T) {
+	assert := assert.New(t)
+	c, fake := newFakeCron(t, 2)
+
+	job := newBlockingJob()
+	_, err := c.Add("@every 1m", OverlapReplace, job.run)
+	assert.NoError(err)
+
+	fake.Advance(time.Minute)
+	assert.Equal(1, receive(t, job.started))
+
+	// 첫번째 실행은 ctx가 취소되어 끝나고, 두번째 실행이 시작된다
+	fake.Advance(time.Minute)
+	assert.Equal(1, receive(t, job.finished))
+	assert.Equal(2, receive(t, job.started))
+
+	job.release <- struct{}{}
+	assert.Equal(2, receive(t, job.finished))
+}
+
+// 워커 수 만큼만 동시에 실행하고, 나머지는 예정 시각 순으로 기다린다
+func TestCronWorkerPool(t *testing.T) {
+	assert := assert.New(t)
+	c, fake := newFakeCron(t, 2)
+
+	jobs := []*blockingJob{newBlockingJob(), newBlockingJob(), newBlockingJob()}
+	for i, job := range jobs {
+		_, err := c.Add("@every 1m", OverlapSkip, job.run)
+		assert.NoError(err)
+		// 작업마다 예정 시각이 1초씩 늦다
+		if i < len(jobs)-1 {
+			fake.Advance(time.Second)
+		}
+	}
+
+	fake.Advance(time.Minute)
+	receive(t, jobs[0].started)
+	receive(t, jobs[1].started)
+	assertEmpty(t, jobs[2].started)
+
+	jobs[0].release <- struct{}{}
+	receive(t, jobs[2].started)
+	jobs[1].release <- struct{}{}
+	jobs[2].release <- struct{}{}
+}
+
+// 작업이 panic 해도 워커는 계속 실행하고, 다음 실행도 건너뛰지 않는다
+func TestCronShouldRecoverPanickingJob(t *testing.T) {
+	assert := assert.New(t)
+	c, fake := newFakeCron(t, 1)
+
+	runs := make(chan int, 10)
+	n := 0
+	id, err := c.Add("@every 1m", OverlapSkip, func(context.Context) {
+		n++
+		runs <- n
+		panic("boom")
+	})
+	assert.NoError(err)
+
+	for i := 1; i <= 3; i++ {
+		fake.Advance(time.Minute)
+		assert.Equal(i, receive(t, runs))
+		// panic 후에도 실행을 마친다(finish)
+		assert.Eventually(func() bool {
+			e, _ := c.Entry(id)
+			return e.Panics == i && e.Active == 0
+		}, time.Second, time.Millisecond)
+	}
+
+	e, _ := c.Entry(id)
+	assert.Equal(3, e.Runs)
+	assert.Zero(e.Skipped)
+	assert.Zero(e.Active)
+}
+
+// Remove 후에는 실행하지 않고, Stop은 실행중인 작업을 취소하고 기다린다
+func TestCronRemoveAndStop(t *testing.T) {
+	assert := assert.New(t)
+	c, fake := newFakeCron(t, 2)
+
+	removed := make(chan struct{}, 10)
+	id, err := c.Add("@every 1m", OverlapSkip, func(context.Context) { removed <- struct{}{} })
+	assert.NoError(err)
+	assert.True(c.Remove(id))
+	assert.False(c.Remove(id))
+
+	job := newBlockingJob()
+	_, err = c.Add("@every 1m", OverlapSkip, job.run)
+	assert.NoError(err)
+
+	fake.Advance(time.Minute)
+	receive(t, job.started)
+	assertEmpty(t, removed)
+
+	c.Stop()
+	// 실행중인 작업은 ctx가 취소되어 끝났다
+	assert.Equal(1, receive(t, job.finished))
+
+	_, err = c.Add("@every 1m", OverlapSkip, job.run)
+	assert.ErrorIs(err, ErrStopped)
+	c.Stop()
+}
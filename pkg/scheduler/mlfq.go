@@ -0,0 +1,269 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"gostudy/pkg/clock"
+	"gostudy/pkg/pqueue"
+)
+
+// Next로 꺼내지 않았거나 이미 Yield/Complete 된 작업
+var ErrNotRunning = errors.New("task is not running")
+
+// MLFQ 설정
+type Config struct {
+	Levels     int           // 큐 레벨 수(기본값 3)
+	Quantum    time.Duration // 최상위 레벨의 time slice(기본값 10ms), 레벨이 내려갈 때마다 2배
+	BoostEvery time.Duration // 모든 작업을 최상위 레벨로 올리는 주기(0: 사용안함)
+	Cap        int           // 대기중이거나 실행중인 작업의 최대 수(기본값 1024)
+	Clock      clock.Clock   // 시계(기본값 clock.Real)
+}
+
+// 다단계 피드백 큐(multi-level feedback queue) 스케줄러
+//
+// 규칙(OSTEP의 MLFQ)
+//   - 레벨이 높은(숫자가 작은) 작업이 먼저 실행되고, 같은 레벨에서는 우선순위(less), 입력 순서로 실행된다.
+//   - 새 작업은 최상위 레벨(0)에서 시작한다.
+//   - 작업이 한 레벨에서 사용한 시간의 합이 그 레벨의 time slice를 넘으면 한 레벨 내려간다(demote).
+//     짧게 나눠 실행해도 합산하므로, 자주 양보하는 방법으로 높은 레벨에 머물 수 없다.
+//   - BoostEvery 마다 모든 작업을 최상위 레벨로 올린다(boost). 낮은 레벨 작업의 기아(starvation) 방지
+//
+// 워커는 Next로 작업을 꺼내 최대 Slice() 만큼 실행한 뒤, 끝나지 않았으면 Yield(다시 대기),
+// 끝났으면 Complete를 호출한다. 모든 메소드는 concurrent-safe 하다.
+//
+// Cap은 Submit 할 때 작업마다 자리를 예약하고 Complete 할 때 반환한다. 따라서 실행중인 작업은
+// 항상 대기열에 자리가 있어서 Yield는 대기하지 않는다(모든 워커가 Yield 해도 교착되지 않음).
+type MLFQ[T, P any] struct {
+	l          sync.Mutex
+	q          *pqueue.PriorityChannel[*Task[T, P], level[P]]
+	quanta     []time.Duration // 레벨별 time slice
+	clock      clock.Clock
+	boostEvery time.Duration
+	boostedAt  time.Time
+	epoch      uint64                                                // boost 횟수
+	queued     map[*Task[T, P]]*pqueue.Handle[*Task[T, P], level[P]] // 대기중인 작업(boost 용)
+	running    map[*Task[T, P]]struct{}                              // 실행중인 작업
+	slots      chan struct{}                                         // 작업마다 예약한 자리(Submit ~ Complete)
+	done       chan struct{}                                         // 닫히면 close
+	closeOnce  sync.Once
+}
+
+// 큐 우선순위: 레벨, 같은 레벨에서는 작업의 우선순위
+type level[P any] struct {
+	level    int
+	priority P
+}
+
+// 스케줄러 작업
+type Task[T, P any] struct {
+	Data     T
+	Priority P
+
+	level int           // 현재 레벨
+	used  time.Duration // 현재 레벨에서 사용한 시간
+	slice time.Duration // 현재 레벨의 time slice
+	epoch uint64        // 실행을 시작한 시점의 boost 횟수
+}
+
+// 현재 레벨(0: 최상위)
+func (t *Task[T, P]) Level() int {
+	return t.level
+}
+
+// 이번 실행에서 사용할 수 있는 시간(현재 레벨의 time slice - 사용한 시간)
+// Next로 꺼낸 작업에 대해서만 의미가 있다.
+func (t *Task[T, P]) Slice() time.Duration {
+	return t.slice - t.used
+}
+
+// MLFQ 스케줄러 생성
+// less(a, b)는 같은 레벨에서 a가 b보다 먼저 실행되어야 하면 true를 반환한다.
+func NewMLFQ[T, P any](cfg Config, less func(a, b P) bool) *MLFQ[T, P] {
+	if cfg.Levels <= 0 {
+		cfg.Levels = 3
+	}
+	if cfg.Quantum <= 0 {
+		cfg.Quantum = time.Millisecond * 10
+	}
+	if cfg.Cap <= 0 {
+		cfg.Cap = 1024
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = clock.Real
+	}
+
+	quanta := make([]time.Duration, cfg.Levels)
+	for i := range quanta {
+		quanta[i] = cfg.Quantum << i
+	}
+
+	q := pqueue.NewChannelFunc[*Task[T, P]](cfg.Cap, func(a, b level[P]) bool {
+		if a.level != b.level {
+			return a.level < b.level
+		}
+		return less(a.priority, b.priority)
+	})
+	q.SetClock(cfg.Clock)
+	// 자리는 Submit에서 예약하므로 push는 대기하지 않는다
+	q.SetOverflow(pqueue.Reject, nil)
+
+	return &MLFQ[T, P]{
+		q:          q,
+		quanta:     quanta,
+		clock:      cfg.Clock,
+		boostEvery: cfg.BoostEvery,
+		boostedAt:  cfg.Clock.Now(),
+		queued:     map[*Task[T, P]]*pqueue.Handle[*Task[T, P], level[P]]{},
+		running:    map[*Task[T, P]]struct{}{},
+		slots:      make(chan struct{}, cfg.Cap),
+		done:       make(chan struct{}),
+	}
+}
+
+// 새 작업을 최상위 레벨에 추가한다(대기중이거나 실행중인 작업이 Cap 이면 Complete 될 때까지 대기)
+// 닫힌 스케줄러면 pqueue.ErrClosed
+func (s *MLFQ[T, P]) Submit(data T, priority P) (*Task[T, P], error) {
+	select {
+	case <-s.done:
+		return nil, pqueue.ErrClosed
+	default:
+	}
+	select {
+	case s.slots <- struct{}{}:
+	case <-s.done:
+		return nil, pqueue.ErrClosed
+	}
+
+	task := &Task[T, P]{Data: data, Priority: priority, slice: s.quanta[0]}
+	s.l.Lock()
+	defer s.l.Unlock()
+	if err := s.enqueue(task); err != nil {
+		<-s.slots
+		return nil, err
+	}
+	return task, nil
+}
+
+// 작업을 현재 레벨의 큐에 추가한다. 락을 잡은 상태에서 호출
+// 자리는 Submit에서 예약했으므로 대기하지 않는다.
+func (s *MLFQ[T, P]) enqueue(task *Task[T, P]) error {
+	h, err := s.q.Push(task, level[P]{level: task.level, priority: task.Priority})
+	if err != nil {
+		return err
+	}
+	// Next가 이미 꺼냈어도 Next가 락을 잡은 뒤에 지운다
+	s.queued[task] = h
+	return nil
+}
+
+// 실행할 작업을 꺼낸다(작업이 있을때 까지 대기)
+// ctx가 종료되면 ctx.Err(), 닫히고 남은 작업이 없으면 pqueue.ErrClosed
+func (s *MLFQ[T, P]) Next(ctx context.Context) (*Task[T, P], error) {
+	s.l.Lock()
+	if s.boostEvery > 0 && s.clock.Since(s.boostedAt) >= s.boostEvery {
+		s.boost()
+	}
+	s.l.Unlock()
+
+	items, err := s.q.PopN(ctx, 1)
+	if err != nil {
+		return nil, err
+	}
+	task := items[0].Data
+
+	s.l.Lock()
+	defer s.l.Unlock()
+	delete(s.queued, task)
+	s.running[task] = struct{}{}
+	task.epoch = s.epoch
+	return task, nil
+}
+
+// 실행중인 작업이 used 만큼 실행한 후 양보한다(다시 대기)
+// 현재 레벨에서 사용한 시간의 합이 time slice를 넘으면 한 레벨 내려간다.
+// Yield 전에 task.Priority를 바꾸면 같은 레벨 안에서 새 우선순위로 대기한다.
+// 닫힌 스케줄러면 pqueue.ErrClosed를 반환하며, 작업은 그대로 실행중이다(Complete 할 수 있다).
+func (s *MLFQ[T, P]) Yield(task *Task[T, P], used time.Duration) error {
+	s.l.Lock()
+	defer s.l.Unlock()
+	if _, ok := s.running[task]; !ok {
+		return ErrNotRunning
+	}
+	prev := *task
+
+	// 실행중에 boost 되었으면 최상위 레벨로 돌아온다
+	if task.epoch != s.epoch {
+		task.level, task.used = 0, 0
+	}
+	task.used += used
+	if task.used >= s.quanta[task.level] {
+		// 가장 낮은 레벨에서는 round-robin
+		if task.level < len(s.quanta)-1 {
+			task.level++
+		}
+		task.used = 0
+	}
+	task.slice = s.quanta[task.level]
+
+	if err := s.enqueue(task); err != nil {
+		task.level, task.used, task.slice = prev.level, prev.used, prev.slice
+		return err
+	}
+	delete(s.running, task)
+	return nil
+}
+
+// 실행중인 작업을 완료한다.
+func (s *MLFQ[T, P]) Complete(task *Task[T, P]) error {
+	s.l.Lock()
+	defer s.l.Unlock()
+
+	if _, ok := s.running[task]; !ok {
+		return ErrNotRunning
+	}
+	delete(s.running, task)
+	<-s.slots
+	return nil
+}
+
+// 모든 작업을 최상위 레벨로 올린다.
+func (s *MLFQ[T, P]) Boost() {
+	s.l.Lock()
+	defer s.l.Unlock()
+	s.boost()
+}
+
+// 모든 작업을 최상위 레벨로 올린다. 락을 잡은 상태에서 호출
+func (s *MLFQ[T, P]) boost() {
+	s.boostedAt = s.clock.Now()
+	s.epoch++
+
+	for task, h := range s.queued {
+		task.level, task.used, task.slice = 0, 0, s.quanta[0]
+		// 이미 꺼낸 작업이면 ErrNotQueued(Next에서 곧 제거된다)
+		h.Update(level[P]{level: 0, priority: task.Priority})
+	}
+	// 실행중인 작업은 Yield 할 때 최상위 레벨로 돌아온다(epoch 비교)
+}
+
+// 대기중인 작업 수
+func (s *MLFQ[T, P]) Len() int {
+	return s.q.Count()
+}
+
+// 실행중인 작업 수
+func (s *MLFQ[T, P]) Running() int {
+	s.l.Lock()
+	defer s.l.Unlock()
+	return len(s.running)
+}
+
+// 스케줄러를 닫는다. 더 이상 추가(Submit, Yield)할 수 없지만, 대기중인 작업은 꺼낼 수 있다.
+// 자리를 기다리던 Submit은 pqueue.ErrClosed를 반환한다.
+func (s *MLFQ[T, P]) Close() {
+	s.closeOnce.Do(func() { close(s.done) })
+	s.q.Close()
+}
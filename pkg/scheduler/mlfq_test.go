@@ -0,0 +1,289 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"gostudy/pkg/clock"
+	"gostudy/pkg/pqueue"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 작업을 꺼낸다(테스트용, 대기하지 않음)
+func next[T, P any](s *MLFQ[T, P]) *Task[T, P] {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	task, err := s.Next(ctx)
+	if err != nil {
+		return nil
+	}
+	return task
+}
+
+// 같은 레벨에서는 우선순위 순, 우선순위가 같으면 입력 순으로 실행된다
+func TestMLFQShouldRunByPriorityWithinLevel(t *testing.T) {
+	assert := assert.New(t)
+
+	s := NewMLFQ[string](Config{}, pqueue.Less[int])
+	for _, p := range []struct {
+		data     string
+		priority int
+	}{{"c", 3}, {"a", 1}, {"b", 2}, {"a2", 1}} {
+		_, err := s.Submit(p.data, p.priority)
+		assert.NoError(err)
+	}
+
+	for _, expected := range []string{"a", "a2", "b", "c"} {
+		task := next(s)
+		assert.Equal(expected, task.Data)
+		assert.Equal(0, task.Level())
+		assert.NoError(s.Complete(task))
+	}
+	assert.Equal(0, s.Running())
+}
+
+// time slice를 모두 사용하면 한 레벨 내려가고, 높은 레벨의 작업이 먼저 실행된다
+func TestMLFQShouldDemoteTaskExceedingTimeSlice(t *testing.T) {
+	assert := assert.New(t)
+
+	s := NewMLFQ[string](Config{Levels: 3, Quantum: time.Millisecond * 10}, pqueue.Less[int])
+	s.Submit("long", 0)
+
+	long := next(s)
+	assert.Equal(time.Millisecond*10, long.Slice())
+	assert.NoError(s.Yield(long, time.Millisecond*10))
+	assert.Equal(1, long.Level())
+	assert.Equal(time.Millisecond*20, long.Slice())
+
+	// 새 작업은 최상위 레벨이므로 먼저 실행된다
+	s.Submit("short", 9)
+	task := next(s)
+	assert.Equal("short", task.Data)
+	assert.NoError(s.Complete(task))
+
+	// 가장 낮은 레벨 아래로는 내려가지 않는다
+	task = next(s)
+	assert.Equal("long", task.Data)
+	assert.NoError(s.Yield(task, time.Millisecond*20))
+	task = next(s)
+	assert.Equal(2, task.Level())
+	assert.NoError(s.Yield(task, time.Millisecond*100))
+	task = next(s)
+	assert.Equal(2, task.Level())
+	assert.Equal(time.Millisecond*40, task.Slice())
+	assert.NoError(s.Complete(task))
+}
+
+// 짧게 나눠 양보해도 한 레벨에서 사용한 시간을 합산한다(gaming 방지)
+func TestMLFQShouldAccumulateUsedTimeWithinLevel(t *testing.T) {
+	assert := assert.New(t)
+
+	s := NewMLFQ[string](Config{Quantum: time.Millisecond * 10}, pqueue.Less[int])
+	s.Submit("io", 0)
+
+	for i := 0; i < 4; i++ {
+		task := next(s)
+		assert.Equal(0, task.Level())
+		assert.Equal(time.Millisecond*time.Duration(10-i*2), task.Slice())
+		assert.NoError(s.Yield(task, time.Millisecond*2))
+	}
+	task := next(s)
+	assert.NoError(s.Yield(task, time.Millisecond*2))
+	assert.Equal(1, task.Level())
+}
+
+// boost는 대기중인 작업과 실행중인 작업을 모두 최상위 레벨로 올린다
+func TestMLFQBoostShouldMoveAllTasksToTopLevel(t *testing.T) {
+	assert := assert.New(t)
+
+	s := NewMLFQ[string](Config{Quantum: time.Millisecond}, pqueue.Less[int])
+	s.Submit("a", 0)
+	s.Submit("b", 0)
+
+	a := next(s)
+	assert.NoError(s.Yield(a, time.Millisecond))
+	assert.Equal(1, a.Level())
+
+	b := next(s)
+	assert.Equal("b", b.Data)
+	s.Submit("c", 0)
+
+	s.Boost()
+
+	// 대기중인 작업(a)은 바로 최상위 레벨, 입력 순서는 유지된다
+	task := next(s)
+	assert.Equal("a", task.Data)
+	assert.Equal(0, task.Level())
+	assert.NoError(s.Complete(task))
+
+	// 실행중이던 작업(b)은 Yield 할 때 최상위 레벨로 돌아온다
+	assert.NoError(s.Yield(b, 0))
+	assert.Equal(0, b.Level())
+	assert.Equal("c", next(s).Data)
+	assert.Equal("b", next(s).Data)
+}
+
+// BoostEvery 마다 Next에서 boost 한다
+func TestMLFQShouldBoostPeriodically(t *testing.T) {
+	assert := assert.New(t)
+
+	fake := clock.NewFake(epoch)
+	s := NewMLFQ[string](Config{Quantum: time.Millisecond, BoostEvery: time.Millisecond * 20, Clock: fake}, pqueue.Less[int])
+	s.Submit("low", 0)
+	low := next(s)
+	assert.NoError(s.Yield(low, time.Millisecond))
+	assert.Equal(1, low.Level())
+
+	fake.Advance(time.Millisecond * 30)
+	s.Submit("new", 0)
+
+	// boost 후에는 같은 레벨이므로 먼저 입력된 low가 먼저 실행된다
+	task := next(s)
+	assert.Equal("low", task.Data)
+	assert.Equal(0, task.Level())
+}
+
+// Next로 꺼내지 않은 작업은 Yield, Complete 할 수 없다
+func TestMLFQShouldRejectNotRunningTask(t *testing.T) {
+	assert := assert.New(t)
+
+	s := NewMLFQ[string](Config{}, pqueue.Less[int])
+	task, err := s.Submit("a", 0)
+	assert.NoError(err)
+	assert.Equal(ErrNotRunning, s.Complete(task))
+	assert.Equal(ErrNotRunning, s.Yield(task, 0))
+
+	task = next(s)
+	assert.NoError(s.Complete(task))
+	assert.Equal(ErrNotRunning, s.Complete(task))
+}
+
+// 닫힌 스케줄러는 대기중인 작업을 모두 꺼낸 후 ErrClosed
+func TestMLFQCloseShouldDrainTasks(t *testing.T) {
+	assert := assert.New(t)
+
+	s := NewMLFQ[string](Config{}, pqueue.Less[int])
+	s.Submit("a", 0)
+	task := next(s)
+	s.Close()
+
+	_, err := s.Submit("b", 0)
+	assert.Equal(pqueue.ErrClosed, err)
+
+	// 닫힌 후 Yield 한 작업은 그대로 실행중이며 Complete 할 수 있다
+	assert.Equal(pqueue.ErrClosed, s.Yield(task, time.Hour))
+	assert.Equal(1, s.Running())
+	assert.Zero(task.Level())
+	assert.NoError(s.Complete(task))
+
+	_, err = s.Next(context.Background())
+	assert.Equal(pqueue.ErrClosed, err)
+}
+
+// Cap 만큼 실행중이어도 Yield는 대기하지 않고, Submit은 Complete 될 때까지 대기한다
+func TestMLFQYieldShouldNotBlockAtCap(t *testing.T) {
+	assert := assert.New(t)
+
+	s := NewMLFQ[string](Config{Cap: 2}, pqueue.Less[int])
+	s.Submit("a", 0)
+	s.Submit("b", 0)
+	a, b := next(s), next(s)
+
+	submitted := make(chan error)
+	go func() {
+		_, err := s.Submit("c", 0)
+		submitted <- err
+	}()
+
+	// 모든 워커가 Yield 해도 교착되지 않는다
+	assert.NoError(s.Yield(a, 0))
+	assert.NoError(s.Yield(b, 0))
+	assert.Equal(2, s.Len())
+	select {
+	case <-submitted:
+		t.Fatal("submit should block while the scheduler is at cap")
+	case <-time.After(time.Millisecond * 20):
+	}
+
+	// 완료하면 자리가 난다
+	assert.NoError(s.Complete(next(s)))
+	assert.NoError(<-submitted)
+	assert.Equal(2, s.Len())
+
+	// 닫으면 자리를 기다리던 Submit도 끝난다
+	go func() {
+		_, err := s.Submit("d", 0)
+		submitted <- err
+	}()
+	s.Close()
+	assert.Equal(pqueue.ErrClosed, <-submitted)
+}
+
+// 여러 워커가 작업을 나눠 실행해도 모든 작업이 정확히 한번 완료된다
+func TestMLFQShouldBeConcurrentSafe(t *testing.T) {
+	assert := assert.New(t)
+
+	const tasks, workers = 200, 8
+	s := NewMLFQ[int](Config{Quantum: time.Microsecond * 100, BoostEvery: time.Millisecond}, pqueue.Less[int])
+
+	// 작업마다 남은 실행 시간
+	remaining := make([]time.Duration, tasks)
+	for i := range remaining {
+		remaining[i] = time.Microsecond * time.Duration(50*(i%10+1))
+		_, err := s.Submit(i, i%5)
+		assert.NoError(err)
+	}
+
+	completed := make([]int, tasks)
+	var l sync.Mutex
+	var wg sync.WaitGroup
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := 0
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				task, err := s.Next(ctx)
+				if err != nil {
+					return
+				}
+
+				l.Lock()
+				used := remaining[task.Data]
+				if used > task.Slice() {
+					used = task.Slice()
+				}
+				remaining[task.Data] -= used
+				finished := remaining[task.Data] == 0
+				l.Unlock()
+
+				if !finished {
+					assert.NoError(s.Yield(task, used))
+					continue
+				}
+				assert.NoError(s.Complete(task))
+
+				l.Lock()
+				completed[task.Data]++
+				done++
+				if done == tasks {
+					cancel()
+				}
+				l.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i := range completed {
+		assert.Equal(1, completed[i])
+	}
+	assert.Equal(0, s.Len())
+	assert.Equal(0, s.Running())
+}
@@ -0,0 +1,231 @@
+// 이산 사건 시뮬레이션(discrete-event simulation)
+//
+// 실제로 기다리는(time.Sleep) 대신 이벤트를 가상 시각 순으로 처리하며, 이벤트를 처리할 때마다
+// 가상 시계를 그 이벤트의 시각으로 옮긴다. 이벤트 사이의 시간은 기다리지 않으므로,
+// 몇 분 걸리는 모델도 밀리초 안에 끝나고 같은 입력이면 항상 같은 결과가 나온다.
+package sim
+
+import (
+	"container/heap"
+	"time"
+
+	"gostudy/pkg/clock"
+)
+
+// 가상 시계(clock.Clock)로 사용할 수 있다.
+var _ clock.Clock = (*Engine)(nil)
+
+// 이벤트
+type Event struct {
+	at    time.Time
+	seq   uint64 // 예약 순서(같은 시각이면 먼저 예약한 이벤트부터)
+	fn    func()
+	index int // index in the event heap (-1: 처리되었거나 취소됨)
+}
+
+// 이벤트 시각
+func (ev *Event) At() time.Time {
+	return ev.at
+}
+
+// 예약되어 있나?(처리되거나 취소되지 않았나?)
+func (ev *Event) Scheduled() bool {
+	return ev.index >= 0
+}
+
+// 시뮬레이션 엔진
+//
+// 이벤트 핸들러는 Run을 호출한 고루틴에서 하나씩 실행되며, 핸들러 안에서 새 이벤트를 예약하거나
+// 취소할 수 있다. 하나의 고루틴에서 사용한다(concurrent-safe 하지 않음).
+type Engine struct {
+	start   time.Time
+	now     time.Time
+	events  eventHeap
+	seq     uint64
+	fired   uint64 // 처리한 이벤트 수
+	stopped bool
+}
+
+// start 시각에서 시작하는 엔진 생성
+func New(start time.Time) *Engine {
+	return &Engine{start: start, now: start}
+}
+
+// 현재 가상 시각
+func (e *Engine) Now() time.Time {
+	return e.now
+}
+
+// t 이후로 지난 가상 시간
+func (e *Engine) Since(t time.Time) time.Duration {
+	return e.now.Sub(t)
+}
+
+// 시작 후 지난 가상 시간
+func (e *Engine) Elapsed() time.Duration {
+	return e.now.Sub(e.start)
+}
+
+// 처리한 이벤트 수
+func (e *Engine) Fired() uint64 {
+	return e.fired
+}
+
+// 대기중인 이벤트 수
+func (e *Engine) Pending() int {
+	return e.events.Len()
+}
+
+// d 후에 fn을 실행하도록 예약한다(d <= 0 이면 현재 시각)
+func (e *Engine) Schedule(d time.Duration, fn func()) *Event {
+	return e.ScheduleAt(e.now.Add(d), fn)
+}
+
+// at 시각에 fn을 실행하도록 예약한다. 이미 지난 시각이면 현재 시각에 실행한다.
+func (e *Engine) ScheduleAt(at time.Time, fn func()) *Event {
+	if at.Before(e.now) {
+		at = e.now
+	}
+	e.seq++
+	ev := &Event{at: at, seq: e.seq, fn: fn}
+	heap.Push(&e.events, ev)
+	return ev
+}
+
+// 예약된 이벤트를 취소한다. 이미 처리되었거나 취소된 이벤트면 false
+func (e *Engine) Cancel(ev *Event) bool {
+	if ev == nil || ev.index < 0 {
+		return false
+	}
+	heap.Remove(&e.events, ev.index)
+	return true
+}
+
+// 가장 빠른 이벤트 하나를 처리한다. 처리할 이벤트가 없으면 false
+func (e *Engine) Step() bool {
+	if e.events.Len() == 0 {
+		return false
+	}
+	ev := heap.Pop(&e.events).(*Event)
+	e.now = ev.at
+	e.fired++
+	ev.fn()
+	return true
+}
+
+// 이벤트가 없거나 Stop 할 때까지 처리한다.
+func (e *Engine) Run() {
+	e.stopped = false
+	for !e.stopped && e.Step() {
+	}
+}
+
+// until 시각까지의 이벤트를 처리하고 가상 시계를 until로 옮긴다(Stop 하면 그 시각에서 멈춘다).
+func (e *Engine) RunUntil(until time.Time) {
+	e.stopped = false
+	for !e.stopped && e.events.Len() > 0 && !e.events[0].at.After(until) {
+		e.Step()
+	}
+	if !e.stopped && until.After(e.now) {
+		e.now = until
+	}
+}
+
+// Run, RunUntil을 멈춘다(이벤트 핸들러에서 호출). 남은 이벤트는 그대로 남는다.
+func (e *Engine) Stop() {
+	e.stopped = true
+}
+
+/////////////////////////////////////////////////////////////////////////
+// clock.Clock
+// 타이머는 이벤트로 동작하며, 만료되면 버퍼(1)가 있는 채널에 가상 시각을 보낸다.
+/////////////////////////////////////////////////////////////////////////
+
+// d 후에 가상 시각을 전달하는 채널
+func (e *Engine) After(d time.Duration) <-chan time.Time {
+	return e.NewTimer(d).C()
+}
+
+// 시뮬레이션은 하나의 고루틴에서 이벤트를 처리하므로 대기할 수 없다(panic).
+// 대신 Schedule로 이후의 동작을 예약한다.
+func (e *Engine) Sleep(d time.Duration) {
+	panic("sim: Sleep would block the simulation, use Schedule instead")
+}
+
+// d 후에 만료되는 타이머
+func (e *Engine) NewTimer(d time.Duration) clock.Timer {
+	t := &timer{e: e, c: make(chan time.Time, 1)}
+	t.ResetAt(e.now.Add(d))
+	return t
+}
+
+// 가상 타이머
+type timer struct {
+	e  *Engine
+	c  chan time.Time
+	ev *Event
+}
+
+func (t *timer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *timer) Stop() bool {
+	return t.e.Cancel(t.ev)
+}
+
+func (t *timer) Reset(d time.Duration) bool {
+	return t.ResetAt(t.e.now.Add(d))
+}
+
+func (t *timer) ResetAt(deadline time.Time) bool {
+	active := t.Stop()
+	t.ev = t.e.ScheduleAt(deadline, func() {
+		// 읽지 않은 이전 값이 있으면 버리고 새 시각을 보낸다(재설정한 타이머가 지난 시각을 전달하지 않게)
+		select {
+		case <-t.c:
+		default:
+		}
+		t.c <- t.e.now
+	})
+	return active
+}
+
+/////////////////////////////////////////////////////////////////////////
+// 이벤트 힙(heap.Interface): 시각, 예약 순서 순
+/////////////////////////////////////////////////////////////////////////
+
+type eventHeap []*Event
+
+func (h eventHeap) Len() int {
+	return len(h)
+}
+
+func (h eventHeap) Less(i, j int) bool {
+	if !h[i].at.Equal(h[j].at) {
+		return h[i].at.Before(h[j].at)
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h eventHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *eventHeap) Push(x interface{}) {
+	ev := x.(*Event)
+	ev.index = len(*h)
+	*h = append(*h, ev)
+}
+
+func (h *eventHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	ev := old[n-1]
+	old[n-1] = nil // avoid memory leak
+	ev.index = -1
+	*h = old[:n-1]
+	return ev
+}
@@ -0,0 +1,147 @@
+package sim
+
+import (
+	"testing"
+	"time"
+
+	"gostudy/pkg/clock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var epoch = time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)
+
+// 이벤트는 시각 순, 같은 시각이면 예약 순으로 처리되고 가상 시계가 이벤트 시각으로 이동한다
+func TestEngineShouldFireEventsInTimeOrder(t *testing.T) {
+	assert := assert.New(t)
+	e := New(epoch)
+
+	var fired []string
+	var at []time.Duration
+	record := func(name string) func() {
+		return func() {
+			fired = append(fired, name)
+			at = append(at, e.Elapsed())
+		}
+	}
+	e.Schedule(time.Second*3, record("c"))
+	e.Schedule(time.Second, record("a1"))
+	e.Schedule(time.Second, record("a2"))
+	e.ScheduleAt(epoch.Add(time.Second*2), record("b"))
+	assert.Equal(4, e.Pending())
+
+	e.Run()
+	assert.Equal([]string{"a1", "a2", "b", "c"}, fired)
+	assert.Equal([]time.Duration{time.Second, time.Second, time.Second * 2, time.Second * 3}, at)
+	assert.Equal(epoch.Add(time.Second*3), e.Now())
+	assert.Equal(uint64(4), e.Fired())
+	assert.Zero(e.Pending())
+}
+
+// 이벤트 핸들러에서 새 이벤트를 예약할 수 있고, 지난 시각은 현재 시각으로 처리한다
+func TestEngineShouldScheduleFromHandler(t *testing.T) {
+	assert := assert.New(t)
+	e := New(epoch)
+
+	ticks := 0
+	var tick func()
+	tick = func() {
+		ticks++
+		if ticks < 1000 {
+			e.Schedule(time.Minute, tick)
+		}
+	}
+	e.Schedule(time.Minute, tick)
+
+	past := false
+	e.Schedule(time.Hour, func() {
+		e.ScheduleAt(epoch, func() {
+			past = true
+			assert.Equal(time.Hour, e.Elapsed())
+		})
+	})
+
+	e.Run()
+	assert.Equal(1000, ticks)
+	assert.True(past)
+	assert.Equal(time.Minute*1000, e.Elapsed())
+}
+
+// 취소된 이벤트는 처리되지 않는다
+func TestEngineCancel(t *testing.T) {
+	assert := assert.New(t)
+	e := New(epoch)
+
+	fired := 0
+	ev := e.Schedule(time.Second, func() { fired++ })
+	e.Schedule(time.Second*2, func() { fired++ })
+	assert.True(ev.Scheduled())
+	assert.True(e.Cancel(ev))
+	assert.False(e.Cancel(ev))
+	assert.False(ev.Scheduled())
+
+	e.Run()
+	assert.Equal(1, fired)
+	assert.Equal(time.Second*2, e.Elapsed())
+}
+
+// RunUntil은 주어진 시각까지만 처리하고, Stop은 처리를 멈춘다
+func TestEngineRunUntilAndStop(t *testing.T) {
+	assert := assert.New(t)
+	e := New(epoch)
+
+	fired := []int{}
+	for i := 1; i <= 5; i++ {
+		i := i
+		e.Schedule(time.Second*time.Duration(i), func() {
+			fired = append(fired, i)
+			if i == 4 {
+				e.Stop()
+			}
+		})
+	}
+
+	e.RunUntil(epoch.Add(time.Millisecond * 2500))
+	assert.Equal([]int{1, 2}, fired)
+	assert.Equal(time.Millisecond*2500, e.Elapsed())
+
+	e.Run()
+	assert.Equal([]int{1, 2, 3, 4}, fired)
+	assert.Equal(time.Second*4, e.Elapsed())
+	assert.Equal(1, e.Pending())
+
+	e.Run()
+	assert.Equal([]int{1, 2, 3, 4, 5}, fired)
+}
+
+// 엔진은 가상 시계(clock.Clock)로 사용할 수 있다
+func TestEngineAsClock(t *testing.T) {
+	assert := assert.New(t)
+	e := New(epoch)
+	var c clock.Clock = e
+
+	timer := c.NewTimer(time.Second)
+	after := c.After(time.Second * 2)
+
+	e.RunUntil(epoch.Add(time.Second))
+	assert.Equal(epoch.Add(time.Second), <-timer.C())
+	assert.Len(after, 0)
+
+	// 재설정, 멈춤
+	assert.False(timer.Reset(time.Second * 5))
+	assert.True(timer.Stop())
+	e.Run()
+	assert.Equal(epoch.Add(time.Second*2), <-after)
+	assert.Len(timer.C(), 0)
+	assert.Equal(time.Second, c.Since(epoch.Add(time.Second)))
+
+	assert.Panics(func() { c.Sleep(time.Second) })
+
+	// 읽지 않은 값이 있으면 새 시각으로 바뀐다
+	timer.Reset(time.Second)
+	e.Run()
+	timer.Reset(time.Second)
+	e.Run()
+	assert.Equal(e.Now(), <-timer.C())
+	assert.Len(timer.C(), 0)
+}
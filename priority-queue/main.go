@@ -1,8 +1,15 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
+	"gostudy/pkg/clock"
+	"gostudy/pkg/pqueue"
 	"gostudy/pkg/rng"
+	"gostudy/pkg/scheduler"
+	"math/rand"
+	"os"
 	"runtime"
 	"sync"
 	"sync/atomic"
@@ -20,9 +27,34 @@ type Patient struct {
 	visitAt time.Time
 }
 
-// 환자가 죽었나?
-func (p Patient) IsDead() bool {
-	return int(time.Now().Sub(p.visitAt).Seconds()) >= p.hp
+// 응급 환자 우선순위
+// hp가 낮을수록 우선, hp가 같으면 나이가 많을수록 우선
+type Triage struct {
+	hp  int
+	age int
+}
+
+// 우선순위 비교(PriorityChannel의 less 함수)
+func (a Triage) Less(b Triage) bool {
+	if a.hp != b.hp {
+		return a.hp < b.hp
+	}
+	return a.age > b.age
+}
+
+// 환자의 우선순위
+func (p Patient) Triage() Triage {
+	return Triage{hp: p.hp, age: p.age}
+}
+
+// 환자의 마감 시간(이 시간까지 치료받지 못하면 사망)
+func (p Patient) Deadline() time.Time {
+	return p.visitAt.Add(time.Second * time.Duration(p.hp))
+}
+
+// 환자가 now 시점에 죽었나?
+func (p Patient) IsDead(now time.Time) bool {
+	return pqueue.ExpiredAt(p.Deadline(), now)
 }
 
 const (
@@ -30,15 +62,23 @@ const (
 )
 
 // 우선순위 큐를 사용한 예
-func runWithPriorityQueue() {
+// 마감 시간이 빠른 환자부터 치료하고(EDF), 마감 시간이 지난 환자는 대기열에서 바로 사망 처리한다.
+func runWithPriorityQueue(clk clock.Clock) {
+	// 사망자수, 처치 수
+	var dead, cured int64
+
 	// 응급 환자 큐
-	patients := NewChannel[Patient](PATIENT_COUNT)
+	patients := pqueue.NewDeadlineChannel(PATIENT_COUNT, func(item pqueue.Item[Patient, time.Time]) {
+		fmt.Printf("critical!!! patient dead!!! %+v\n", item.Data)
+		atomic.AddInt64(&dead, 1)
+	})
+	patients.SetClock(clk)
 
 	wg := sync.WaitGroup{}
 	doctors := runtime.NumCPU() / 2
 
 	// 시작 시간
-	begin := time.Now()
+	begin := clk.Now()
 
 	// 환자 발생!!!
 	wg.Add(1)
@@ -46,19 +86,17 @@ func runWithPriorityQueue() {
 		defer wg.Done()
 		for i := 0; i < PATIENT_COUNT; i++ {
 
-			// 랜덤한 환자 생성( hp: 10-90 )
-			patient := Patient{id: i, hp: rng.NextInRange(10, 90), visitAt: time.Now()}
+			// 랜덤한 환자 생성( hp: 10-90, age: 1-99 )
+			patient := Patient{id: i, age: rng.NextInRange(1, 100), hp: rng.NextInRange(10, 90), visitAt: clk.Now()}
 
 			// 환자 대기열에 추가
-			if err := patients.Push(patient, patient.hp); err != nil {
+			if _, err := patients.Push(patient, patient.Deadline()); err != nil {
 				fmt.Printf("enque: err=%v", err)
 				continue
 			}
 		}
 	}()
 
-	// 사망자수, 처치 수
-	var dead, cured int64
 	treated := make([]int, doctors)
 
 	// 환자 진료
@@ -70,23 +108,16 @@ func runWithPriorityQueue() {
 			defer fmt.Printf("doctor[%d] says: I'm done!\n", doctor)
 
 			// 잠시 대기
-			time.Sleep(time.Second * 1)
+			clk.Sleep(time.Second * 1)
 
 			for {
-				// 환자 대기열에서 환자를 호출
+				// 환자 대기열에서 환자를 호출(마감 시간이 지난 환자는 나오지 않는다)
 				patient, err := patients.Deque()
 				if err != nil {
 					// fmt.Printf("doctor[%d] says: no more patients!\n", doctor)
 					break
 				}
 
-				// 죽었나?
-				if patient.IsDead() {
-					fmt.Printf("critical!!! patient dead!!! %+v\n", patient)
-					atomic.AddInt64(&dead, 1)
-					continue
-				}
-
 				// 치료한다.
 				treattime := 100 - patient.hp
 				patient.hp = 100
@@ -95,7 +126,7 @@ func runWithPriorityQueue() {
 				// fmt.Printf("doctor[%d] cured patient[%+v]\n", doctor, patient)
 
 				// 치료 시간만큼 대기
-				time.Sleep(time.Millisecond * time.Duration(treattime))
+				clk.Sleep(time.Millisecond * time.Duration(treattime))
 			}
 		}(i)
 	}
@@ -104,12 +135,124 @@ func runWithPriorityQueue() {
 	wg.Wait()
 
 	// 결과 출력
-	fmt.Printf("dead: %d, cured: %d, elapsed: %.2f(s)\n", dead, cured, time.Since(begin).Seconds())
+	fmt.Printf("dead: %d, cured: %d, elapsed: %.2f(s)\n", dead, cured, clk.Since(begin).Seconds())
+	fmt.Printf("doctor treated: %+v\n", treated)
+}
+
+// 다단계 피드백 큐(MLFQ) 스케줄러를 사용한 예
+// 환자를 time slice 만큼만 치료(hp 회복)하고, 치료가 남았으면 다시 대기열에 넣는다.
+// 치료 시간이 긴(hp가 높은) 환자는 낮은 레벨로 내려가고, 위급한 환자가 먼저 치료받는다.
+func runWithMLFQ(clk clock.Clock) {
+	// 응급 환자 큐
+	patients := scheduler.NewMLFQ[*Patient](scheduler.Config{
+		Levels:     3,
+		Quantum:    time.Millisecond * 20,
+		BoostEvery: time.Second,
+		Cap:        PATIENT_COUNT,
+		Clock:      clk,
+	}, Triage.Less)
+
+	wg := sync.WaitGroup{}
+	doctors := runtime.NumCPU() / 2
+
+	// 모든 환자를 처리하면 종료
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// 시작 시간
+	begin := clk.Now()
+
+	// 사망자수, 처치 수, 대기열에 넣지 못한 환자 수
+	var dead, cured, failed int64
+	treated := make([]int, doctors)
+
+	// 모든 환자를 처리했나?
+	finish := func() {
+		if atomic.LoadInt64(&dead)+atomic.LoadInt64(&cured)+atomic.LoadInt64(&failed) == PATIENT_COUNT {
+			cancel()
+		}
+	}
+
+	// 환자 발생!!!
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < PATIENT_COUNT; i++ {
+
+			// 랜덤한 환자 생성( hp: 10-90, age: 1-99 )
+			patient := &Patient{id: i, age: rng.NextInRange(1, 100), hp: rng.NextInRange(10, 90), visitAt: clk.Now()}
+
+			// 환자 대기열에 추가
+			if _, err := patients.Submit(patient, patient.Triage()); err != nil {
+				fmt.Printf("submit: err=%v\n", err)
+				atomic.AddInt64(&failed, 1)
+				finish()
+			}
+		}
+	}()
+
+	// 환자 진료
+	wg.Add(doctors)
+	for i := 0; i < doctors; i++ {
+		go func(doctor int) {
+			defer wg.Done()
+			fmt.Printf("doctor[%d] started to cure patients...\n", doctor)
+			defer fmt.Printf("doctor[%d] says: I'm done!\n", doctor)
+
+			for {
+				// 환자 대기열에서 환자를 호출
+				task, err := patients.Next(ctx)
+				if err != nil {
+					break
+				}
+				patient := task.Data
+
+				// 죽었나?
+				if patient.IsDead(clk.Now()) {
+					fmt.Printf("critical!!! patient dead!!! %+v\n", *patient)
+					patients.Complete(task)
+					atomic.AddInt64(&dead, 1)
+					finish()
+					continue
+				}
+
+				// time slice 만큼만 치료한다.
+				treattime := time.Millisecond * time.Duration(100-patient.hp)
+				if treattime > task.Slice() {
+					treattime = task.Slice()
+				}
+				clk.Sleep(treattime)
+				treated[doctor]++ // 부분 치료 포함
+
+				// 치료한 만큼 hp 회복, 치료가 남았으면 회복된 hp로 다시 대기
+				patient.hp += int(treattime / time.Millisecond)
+				if patient.hp < 100 {
+					task.Priority = patient.Triage()
+					if err := patients.Yield(task, treattime); err != nil {
+						fmt.Printf("yield: err=%v\n", err)
+						patients.Complete(task)
+						atomic.AddInt64(&failed, 1)
+						finish()
+					}
+					continue
+				}
+				patients.Complete(task)
+				atomic.AddInt64(&cured, 1)
+				finish()
+			}
+		}(i)
+	}
+
+	// wait for all go-routine done
+	wg.Wait()
+
+	// 결과 출력
+	fmt.Printf("dead: %d, cured: %d, failed: %d, elapsed: %.2f(s)\n", dead, cured, failed, clk.Since(begin).Seconds())
 	fmt.Printf("doctor treated: %+v\n", treated)
 }
 
 // Go 채널을 사용한 예
-func runWithGoChannel() {
+func runWithGoChannel(clk clock.Clock) {
 	// 응급 환자 큐
 	patients := make(chan Patient, PATIENT_COUNT)
 
@@ -117,7 +260,7 @@ func runWithGoChannel() {
 	doctors := runtime.NumCPU() / 2
 
 	// 시작 시간
-	begin := time.Now()
+	begin := clk.Now()
 
 	// 환자 발생!!!
 	wg.Add(1)
@@ -125,8 +268,8 @@ func runWithGoChannel() {
 		defer wg.Done()
 		for i := 0; i < PATIENT_COUNT; i++ {
 
-			// 랜덤한 환자 생성( hp: 10-90 )
-			patient := Patient{id: i, hp: rng.NextInRange(10, 90), visitAt: time.Now()}
+			// 랜덤한 환자 생성( hp: 10-90, age: 1-99 )
+			patient := Patient{id: i, age: rng.NextInRange(1, 100), hp: rng.NextInRange(10, 90), visitAt: clk.Now()}
 
 			// 환자 대기열에 추가
 			patients <- patient
@@ -149,12 +292,12 @@ func runWithGoChannel() {
 			defer fmt.Printf("doctor[%d] says: I'm done!\n", doctor)
 
 			// 잠시 대기
-			time.Sleep(time.Second * 1)
+			clk.Sleep(time.Second * 1)
 
 			// 대기열에서 환자를 호출
 			for patient := range patients {
 				// 죽었나?
-				if patient.IsDead() {
+				if patient.IsDead(clk.Now()) {
 					fmt.Printf("critical!!! patient dead!!! %+v\n", patient)
 					atomic.AddInt64(&dead, 1)
 					continue
@@ -168,7 +311,7 @@ func runWithGoChannel() {
 				// fmt.Printf("doctor[%d] cured patient[%+v]\n", doctor, patient)
 
 				// 치료 시간만큼 대기
-				time.Sleep(time.Millisecond * time.Duration(treattime))
+				clk.Sleep(time.Millisecond * time.Duration(treattime))
 			}
 		}(i)
 	}
@@ -177,16 +320,173 @@ func runWithGoChannel() {
 	wg.Wait()
 
 	// 결과 출력
-	fmt.Printf("dead: %d, cured: %d, elapsed: %.2f(s)\n", dead, cured, time.Since(begin).Seconds())
+	fmt.Printf("dead: %d, cured: %d, elapsed: %.2f(s)\n", dead, cured, clk.Since(begin).Seconds())
 	fmt.Printf("doctor treated: %+v\n", treated)
 }
 
 func main() {
+	realtime := flag.Bool("realtime", false, "시뮬레이션 대신 실제 시간으로 실행(수십 초 걸림)")
+	trials := flag.Int("trials", 100, "시뮬레이션 시행 횟수")
+	seed := flag.Int64("seed", 1, "시뮬레이션 시드(i번째 시행: seed + i)")
+	doctors := flag.Int("doctors", defaultDoctors(), "시뮬레이션 의사 수")
+	format := flag.String("format", "json", "요약 출력 형식(json, yaml)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] [scenario.yaml|scenario.json ...]\n", os.Args[0])
+		fmt.Fprintln(flag.CommandLine.Output(), "  -trials, -seed, -doctors를 지정하면 시나리오 파일의 값 대신 사용한다.")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	// 명령행에서 지정한 flag
+	set := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	exit := func(err error) {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	// 시나리오 파일을 실행하고 요약을 출력
+	if flag.NArg() > 0 {
+		summaries := make([]Summary, 0, flag.NArg())
+		for _, path := range flag.Args() {
+			sc, err := loadScenario(path)
+			if err == nil {
+				sc, err = sc.override(*trials, *seed, *doctors, func(name string) bool { return set[name] })
+			}
+			if err != nil {
+				exit(fmt.Errorf("%s: %w", path, err))
+			}
+			summaries = append(summaries, runScenario(sc))
+		}
+		if err := printSummaries(os.Stdout, summaries, *format); err != nil {
+			exit(err)
+		}
+		/* OUTPUT
+		D:\gitworks\go-study\priority-queue>go run . scenarios/baseline.yaml
+		{
+		  "scenario": "baseline",
+		  "trials": 100,
+		  "seed": 1,
+		  "patients": 10000,
+		  "doctors": 10,
+		  "discipline": "edf",
+		  "dead": {
+		    "mean": 0,
+		    "min": 0,
+		    "max": 0
+		  },
+		  "cured": {
+		    "mean": 10000,
+		    "min": 10000,
+		    "max": 10000
+		  },
+		  "wait": {
+		    "mean": "32.867391s",
+		    "p50": "36.17308s",
+		    "p90": "49.9962s",
+		    "p99": "51.34492s",
+		    "max": "51.45494s"
+		  },
+		  "utilization": {
+		    "mean": 0.9806,
+		    "min": 0.9804,
+		    "max": 0.9808
+		  },
+		  "elapsed": "51.46594s",
+		  "took": "2.125317s",
+		  "treated": [
+		    1000,
+		    1000,
+		    1000,
+		    1000,
+		    1000,
+		    1000,
+		    1000,
+		    1000,
+		    1000,
+		    1000
+		  ]
+		}
+		*/
+		return
+	}
+
+	// 기본 시나리오에 모든 flag 값(지정하지 않았으면 flag 기본값)을 적용한다
+	base, err := defaultScenario().override(*trials, *seed, *doctors, func(string) bool { return true })
+	if err != nil {
+		exit(err)
+	}
+
 	fmt.Println("Hello, Go!")
 	defer fmt.Println("Bye, Go!")
 
+	// 이산 사건 시뮬레이션으로 실행(가상 시간이므로 바로 끝난다)
+	if !*realtime {
+		fmt.Printf("go channel: %v\n", simulateWithGoChannel(rand.New(rand.NewSource(base.Seed)), PATIENT_COUNT, base.Doctors))
+		fmt.Printf("priority queue: %v\n", simulateWithPriorityQueue(rand.New(rand.NewSource(base.Seed)), PATIENT_COUNT, base.Doctors))
+
+		// 같은 모델을 여러번 시행한 요약
+		var summaries []Summary
+		for _, discipline := range []string{DisciplineFIFO, DisciplineEDF} {
+			sc := base
+			sc.Name, sc.Discipline = discipline, discipline
+			summaries = append(summaries, runScenario(sc))
+		}
+		if err := printSummaries(os.Stdout, summaries, *format); err != nil {
+			fmt.Println(err)
+		}
+		/* OUTPUT
+		D:\gitworks\go-study\priority-queue>go run . -doctors 10 -format yaml
+		Hello, Go!
+		go channel: dead: 1687, cured: 8313, elapsed: 37.99(s), events: 8323
+		priority queue: dead: 0, cured: 10000, elapsed: 51.54(s), events: 10010
+		scenario: fifo
+		trials: 100
+		seed: 1
+		patients: 10000
+		doctors: 10
+		discipline: fifo
+		dead:
+		  mean: 1669.46
+		  min: 1606
+		  max: 1745
+		cured:
+		  mean: 8330.54
+		  min: 8255
+		  max: 8394
+		wait:
+		  mean: 20.629365s
+		  p50: 21.25243s
+		  p90: 34.93825s
+		  p99: 37.72001s
+		  max: 38.02722s
+		utilization:
+		  mean: 0.973
+		  min: 0.9725
+		  max: 0.9736
+		elapsed: 38.07575s
+		took: 606.851ms
+		treated:
+		  - 831.53
+		  - 831.83
+		  - 832.59
+		  - 833.08
+		  - 834.9
+		  - 831.56
+		  - 835.84
+		  - 833.27
+		  - 833.72
+		  - 832.22
+		---
+		scenario: edf
+		...(생략)...
+		Bye, Go!
+		*/
+		return
+	}
+
 	// 채널을 이용한 예를 실행
-	runWithGoChannel()
+	runWithGoChannel(clock.Real)
 	/* OUTPUT
 	D:\gitworks\go-study\priority-queue>go run .
 	Hello, Go!
@@ -245,7 +545,7 @@ func main() {
 	*/
 
 	// 우선순위 큐를 이용한 실행
-	runWithPriorityQueue()
+	runWithPriorityQueue(clock.Real)
 	/* OUTPUT
 	D:\gitworks\go-study\priority-queue>go run .
 	Hello, Go!
@@ -273,4 +573,7 @@ func main() {
 	doctor treated: [1000 1000 1001 1000 999 1000 1000 1001 999 1000]
 	Bye, Go!
 	*/
+
+	// MLFQ 스케줄러를 이용한 실행(부분 치료 후 재대기)
+	runWithMLFQ(clock.Real)
 }
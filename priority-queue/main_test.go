@@ -0,0 +1,77 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"gostudy/pkg/clock"
+	"gostudy/pkg/pqueue"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 환자는 hp가 낮은 순, hp가 같으면 나이가 많은 순으로 호출된다
+func TestPatientShouldBeOrderedByTriage(t *testing.T) {
+	assert := assert.New(t)
+	pc := pqueue.NewChannelFunc[Patient](10, Triage.Less)
+
+	for _, p := range []Patient{
+		{id: 1, hp: 50, age: 30},
+		{id: 2, hp: 20, age: 10},
+		{id: 3, hp: 50, age: 80},
+		{id: 4, hp: 20, age: 10},
+	} {
+		_, err := pc.Push(p, p.Triage())
+		assert.NoError(err)
+	}
+
+	for _, expected := range []int{2, 4, 3, 1} {
+		p, err := pc.Pop()
+		assert.NoError(err)
+		assert.Equal(expected, p.id)
+	}
+}
+
+// 환자는 마감 시간(visitAt + hp초)이 빠른 순으로 호출되고, 마감 시간이 지난 환자는 호출되지 않는다
+func TestPatientShouldBeOrderedByDeadline(t *testing.T) {
+	assert := assert.New(t)
+
+	var dead []int
+	pc := pqueue.NewDeadlineChannel(10, func(item pqueue.Item[Patient, time.Time]) {
+		dead = append(dead, item.Data.id)
+	})
+	fake := clock.NewFake(time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC))
+	pc.SetClock(fake)
+
+	now := fake.Now()
+	for _, p := range []Patient{
+		{id: 1, hp: 50, visitAt: now},
+		{id: 2, hp: 20, visitAt: now.Add(time.Second * 40)},
+		{id: 3, hp: 10, visitAt: now.Add(-time.Second * 10)},
+		{id: 4, hp: 30, visitAt: now},
+	} {
+		_, err := pc.Push(p, p.Deadline())
+		assert.NoError(err)
+	}
+
+	for _, expected := range []int{4, 1, 2} {
+		p, err := pc.Deque()
+		assert.NoError(err)
+		assert.Equal(expected, p.id)
+		assert.False(p.IsDead(fake.Now()))
+	}
+	assert.Equal([]int{3}, dead)
+}
+
+// 환자는 방문 후 hp초가 지나면 사망한다
+func TestPatientShouldDieAfterDeadline(t *testing.T) {
+	assert := assert.New(t)
+
+	fake := clock.NewFake(time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC))
+	p := Patient{id: 1, hp: 30, visitAt: fake.Now()}
+
+	fake.Advance(time.Second*30 - time.Nanosecond)
+	assert.False(p.IsDead(fake.Now()))
+	fake.Advance(time.Nanosecond)
+	assert.True(p.IsDead(fake.Now()))
+}
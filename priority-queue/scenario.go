@@ -0,0 +1,369 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 잘못된 시나리오
+var ErrInvalidScenario = errors.New("invalid scenario")
+
+// 환자 도착 방식
+const (
+	ArrivalBatch   = "batch"   // 모든 환자가 시작할 때 한번에 도착(기본)
+	ArrivalUniform = "uniform" // 일정한 간격(interval)으로 도착
+	ArrivalPoisson = "poisson" // 초당 평균 rate 명이 무작위로 도착(포아송 과정)
+)
+
+// 환자의 hp 분포
+const (
+	SeverityUniform = "uniform" // [min, max) 균등 분포(기본)
+	SeverityNormal  = "normal"  // 평균 mean, 표준편차 stddev 정규 분포([min, max)로 제한)
+)
+
+// 치료 시간 모델
+const (
+	TreatmentLinear      = "linear"      // (full_hp - hp) * unit(기본)
+	TreatmentFixed       = "fixed"       // 모든 환자가 duration
+	TreatmentExponential = "exponential" // 평균 duration 지수 분포
+)
+
+// 대기열 규칙
+const (
+	DisciplineFIFO   = "fifo"   // 도착 순서(Go 채널)
+	DisciplineTriage = "triage" // hp가 낮은 순, 나이가 많은 순(Triage)
+	DisciplineEDF    = "edf"    // 마감 시간이 빠른 순, 마감 시간이 지나면 대기열에서 사망 처리(기본)
+)
+
+// 응급실 시나리오
+//
+// 생략한 값은 runWithPriorityQueue와 같은 모델의 값을 사용한다.
+//
+//	name: rush-hour
+//	patients: 10000
+//	doctors: 10
+//	discipline: edf
+//	arrival:
+//	  process: poisson
+//	  rate: 200
+//	severity:
+//	  distribution: normal
+//	  mean: 40
+//	  stddev: 15
+//	treatment:
+//	  model: linear
+type Scenario struct {
+	Name       string    `json:"name" yaml:"name"`
+	Seed       int64     `json:"seed" yaml:"seed"`               // i번째 시행의 시드: seed + i (기본: 1)
+	Trials     int       `json:"trials" yaml:"trials"`           // 시행 횟수(기본: 1)
+	Patients   int       `json:"patients" yaml:"patients"`       // 환자 수(기본: PATIENT_COUNT)
+	Doctors    int       `json:"doctors" yaml:"doctors"`         // 의사 수(기본: NumCPU/2, 최소 1)
+	StartDelay *Duration `json:"start_delay" yaml:"start_delay"` // 진료 시작까지 대기(기본: 1s)
+	Discipline string    `json:"discipline" yaml:"discipline"`
+	Arrival    Arrival   `json:"arrival" yaml:"arrival"`
+	Severity   Severity  `json:"severity" yaml:"severity"`
+	Treatment  Treatment `json:"treatment" yaml:"treatment"`
+}
+
+// 환자 도착
+type Arrival struct {
+	Process  string   `json:"process" yaml:"process"`
+	Interval Duration `json:"interval" yaml:"interval"` // uniform: 도착 간격
+	Rate     float64  `json:"rate" yaml:"rate"`         // poisson: 초당 평균 도착 수
+}
+
+// 도착한 환자의 hp 분포
+type Severity struct {
+	Distribution string  `json:"distribution" yaml:"distribution"`
+	Min          int     `json:"min" yaml:"min"`       // 기본: 10
+	Max          int     `json:"max" yaml:"max"`       // 기본: 90(포함하지 않음)
+	Mean         float64 `json:"mean" yaml:"mean"`     // normal: 기본 (min + max) / 2
+	Stddev       float64 `json:"stddev" yaml:"stddev"` // normal: 기본 (max - min) / 6
+}
+
+// 치료 시간
+type Treatment struct {
+	Model    string   `json:"model" yaml:"model"`
+	FullHP   int      `json:"full_hp" yaml:"full_hp"`   // linear: 기본 100
+	Unit     Duration `json:"unit" yaml:"unit"`         // linear: hp 1 회복에 걸리는 시간(기본: 1ms)
+	Duration Duration `json:"duration" yaml:"duration"` // fixed: 치료 시간, exponential: 평균 치료 시간
+}
+
+// 기본 시나리오(runWithPriorityQueue와 같은 모델)
+func defaultScenario() Scenario {
+	var sc Scenario
+	sc.setDefaults()
+	return sc
+}
+
+// 기본 의사 수(코어 수의 절반, 최소 1명)
+func defaultDoctors() int {
+	if doctors := runtime.NumCPU() / 2; doctors > 1 {
+		return doctors
+	}
+	return 1
+}
+
+// 생략한 값을 채운다.
+func (sc *Scenario) setDefaults() {
+	if sc.Seed == 0 {
+		sc.Seed = 1
+	}
+	if sc.Trials == 0 {
+		sc.Trials = 1
+	}
+	if sc.Patients == 0 {
+		sc.Patients = PATIENT_COUNT
+	}
+	if sc.Doctors == 0 {
+		sc.Doctors = defaultDoctors()
+	}
+	if sc.StartDelay == nil {
+		delay := Duration(time.Second)
+		sc.StartDelay = &delay
+	}
+	if sc.Discipline == "" {
+		sc.Discipline = DisciplineEDF
+	}
+	if sc.Arrival.Process == "" {
+		sc.Arrival.Process = ArrivalBatch
+	}
+
+	s := &sc.Severity
+	if s.Distribution == "" {
+		s.Distribution = SeverityUniform
+	}
+	if s.Min == 0 && s.Max == 0 {
+		s.Min, s.Max = 10, 90
+	}
+	if s.Distribution == SeverityNormal {
+		if s.Mean == 0 {
+			s.Mean = float64(s.Min+s.Max) / 2
+		}
+		if s.Stddev == 0 {
+			s.Stddev = float64(s.Max-s.Min) / 6
+		}
+	}
+
+	t := &sc.Treatment
+	if t.Model == "" {
+		t.Model = TreatmentLinear
+	}
+	if t.Model == TreatmentLinear {
+		if t.FullHP == 0 {
+			t.FullHP = 100
+		}
+		if t.Unit == 0 {
+			t.Unit = Duration(time.Millisecond)
+		}
+	}
+}
+
+// 시나리오 검사
+func (sc Scenario) validate() error {
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: %s", ErrInvalidScenario, fmt.Sprintf(format, args...))
+	}
+
+	switch {
+	case sc.Trials < 1:
+		return invalid("trials must be positive: %d", sc.Trials)
+	case sc.Patients < 1:
+		return invalid("patients must be positive: %d", sc.Patients)
+	case sc.Doctors < 1:
+		return invalid("doctors must be positive: %d", sc.Doctors)
+	case *sc.StartDelay < 0:
+		return invalid("start_delay must not be negative: %v", *sc.StartDelay)
+	}
+
+	switch sc.Discipline {
+	case DisciplineFIFO, DisciplineTriage, DisciplineEDF:
+	default:
+		return invalid("unknown discipline: %q", sc.Discipline)
+	}
+
+	switch a := sc.Arrival; a.Process {
+	case ArrivalBatch:
+	case ArrivalUniform:
+		if a.Interval <= 0 {
+			return invalid("uniform arrival needs a positive interval")
+		}
+	case ArrivalPoisson:
+		if a.Rate <= 0 {
+			return invalid("poisson arrival needs a positive rate")
+		}
+	default:
+		return invalid("unknown arrival process: %q", a.Process)
+	}
+
+	switch s := sc.Severity; s.Distribution {
+	case SeverityUniform, SeverityNormal:
+		if s.Min < 1 || s.Max <= s.Min {
+			return invalid("severity range must be 1 <= min < max: [%d, %d)", s.Min, s.Max)
+		}
+		if s.Stddev < 0 {
+			return invalid("severity stddev must not be negative: %v", s.Stddev)
+		}
+	default:
+		return invalid("unknown severity distribution: %q", s.Distribution)
+	}
+
+	switch t := sc.Treatment; t.Model {
+	case TreatmentLinear:
+		if t.FullHP < sc.Severity.Max || t.Unit <= 0 {
+			return invalid("linear treatment needs full_hp >= severity max and a positive unit")
+		}
+	case TreatmentFixed, TreatmentExponential:
+		if t.Duration <= 0 {
+			return invalid("%s treatment needs a positive duration", t.Model)
+		}
+	default:
+		return invalid("unknown treatment model: %q", t.Model)
+	}
+	return nil
+}
+
+// 명령행에서 지정한 시행 횟수, 시드, 의사 수로 바꾼 후 검사한다.
+// set(name)이 false인 값은 바꾸지 않는다(시나리오 파일의 값 유지).
+// setDefaults를 거치지 않으므로 0을 지정하면 기본값 대신 오류
+func (sc Scenario) override(trials int, seed int64, doctors int, set func(name string) bool) (Scenario, error) {
+	if set("trials") {
+		sc.Trials = trials
+	}
+	if set("seed") {
+		sc.Seed = seed
+	}
+	if set("doctors") {
+		sc.Doctors = doctors
+	}
+	return sc, sc.validate()
+}
+
+// 시나리오 파일을 읽는다(확장자: .yaml, .yml, .json)
+func loadScenario(path string) (Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Scenario{}, err
+	}
+
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	sc, err := parseScenario(data, format)
+	if err != nil {
+		return Scenario{}, fmt.Errorf("%s: %w", path, err)
+	}
+	if sc.Name == "" {
+		sc.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return sc, nil
+}
+
+// 시나리오를 읽고(format: yaml, yml, json) 생략한 값을 채운 후 검사한다.
+// 알 수 없는 필드가 있으면 오류
+func parseScenario(data []byte, format string) (sc Scenario, err error) {
+	switch format {
+	case "yaml", "yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		err = dec.Decode(&sc)
+	case "json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&sc)
+	default:
+		return sc, fmt.Errorf("%w: unknown format: %q", ErrInvalidScenario, format)
+	}
+	if err != nil {
+		return sc, fmt.Errorf("%w: %v", ErrInvalidScenario, err)
+	}
+
+	sc.setDefaults()
+	return sc, sc.validate()
+}
+
+// 다음 환자가 도착할 때까지의 간격(batch: 0)
+func (a Arrival) next(r *rand.Rand) time.Duration {
+	switch a.Process {
+	case ArrivalUniform:
+		return time.Duration(a.Interval)
+	case ArrivalPoisson:
+		return time.Duration(r.ExpFloat64() / a.Rate * float64(time.Second))
+	}
+	return 0
+}
+
+// 도착한 환자의 hp
+func (s Severity) hp(r *rand.Rand) int {
+	if s.Distribution == SeverityNormal {
+		hp := int(math.Round(s.Mean + r.NormFloat64()*s.Stddev))
+		if hp < s.Min {
+			return s.Min
+		}
+		if hp >= s.Max {
+			return s.Max - 1
+		}
+		return hp
+	}
+	return s.Min + r.Intn(s.Max-s.Min)
+}
+
+// hp인 환자의 치료 시간
+func (t Treatment) duration(r *rand.Rand, hp int) time.Duration {
+	switch t.Model {
+	case TreatmentFixed:
+		return time.Duration(t.Duration)
+	case TreatmentExponential:
+		return time.Duration(r.ExpFloat64() * float64(t.Duration))
+	}
+	return time.Duration(t.Unit) * time.Duration(t.FullHP-hp)
+}
+
+// 시나리오 파일과 요약에서 "1.5s", "100ms" 처럼 쓰는 시간
+type Duration time.Duration
+
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"100ms\": %s", data)
+	}
+	return d.parse(s)
+}
+
+func (d Duration) MarshalYAML() (interface{}, error) {
+	return d.String(), nil
+}
+
+func (d *Duration) UnmarshalYAML(value *yaml.Node) error {
+	var s string
+	if err := value.Decode(&s); err != nil {
+		return err
+	}
+	return d.parse(s)
+}
+
+func (d *Duration) parse(s string) error {
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
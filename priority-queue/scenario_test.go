@@ -0,0 +1,182 @@
+package main
+
+import (
+	"math/rand"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// scenarios 디렉토리의 시나리오 파일은 모두 읽을 수 있다
+func TestLoadScenarioFiles(t *testing.T) {
+	assert := assert.New(t)
+
+	paths, err := filepath.Glob("scenarios/*")
+	assert.NoError(err)
+	assert.NotEmpty(paths)
+	for _, path := range paths {
+		sc, err := loadScenario(path)
+		assert.NoError(err, path)
+		assert.NotEmpty(sc.Name, path)
+	}
+
+	// baseline.yaml은 기본 시나리오(runWithPriorityQueue와 같은 모델)
+	baseline, err := loadScenario("scenarios/baseline.yaml")
+	assert.NoError(err)
+	expected := defaultScenario()
+	expected.Name, expected.Trials, expected.Doctors = "baseline", 100, 10
+	assert.Equal(expected, baseline)
+
+	// fifo.json은 runWithGoChannel과 같은 모델
+	fifo, err := loadScenario("scenarios/fifo.json")
+	assert.NoError(err)
+	expected.Name, expected.Discipline = "fifo", DisciplineFIFO
+	assert.Equal(expected, fifo)
+}
+
+// 생략한 값은 기본값으로 채워진다
+func TestParseScenarioDefaults(t *testing.T) {
+	assert := assert.New(t)
+
+	sc, err := parseScenario([]byte("name: normal\nseverity:\n  distribution: normal\nstart_delay: 0s\n"), "yaml")
+	assert.NoError(err)
+	assert.Equal(PATIENT_COUNT, sc.Patients)
+	assert.Equal(DisciplineEDF, sc.Discipline)
+	assert.Equal(ArrivalBatch, sc.Arrival.Process)
+	assert.Equal(Severity{Distribution: SeverityNormal, Min: 10, Max: 90, Mean: 50, Stddev: 80.0 / 6}, sc.Severity)
+	assert.Equal(Treatment{Model: TreatmentLinear, FullHP: 100, Unit: Duration(time.Millisecond)}, sc.Treatment)
+	assert.Equal(Duration(0), *sc.StartDelay)
+	assert.Positive(sc.Doctors)
+
+	sc, err = parseScenario([]byte(`{"arrival": {"process": "uniform", "interval": "1.5s"}}`), "json")
+	assert.NoError(err)
+	assert.Equal(Duration(time.Millisecond*1500), sc.Arrival.Interval)
+	assert.Equal(Duration(time.Second), *sc.StartDelay)
+}
+
+// 잘못된 시나리오는 ErrInvalidScenario
+func TestParseScenarioInvalid(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, tc := range []struct {
+		format string
+		data   string
+	}{
+		{"yaml", "doctor: 10"},
+		{"json", `{"doctors": 10, "nurses": 3}`},
+		{"yaml", "doctors: -1"},
+		{"yaml", "discipline: lifo"},
+		{"yaml", "arrival: {process: uniform}"},
+		{"yaml", "arrival: {process: poisson, rate: 0}"},
+		{"yaml", "arrival: {process: burst}"},
+		{"yaml", "severity: {min: 50, max: 50}"},
+		{"yaml", "severity: {distribution: normal, stddev: -1}"},
+		{"yaml", "treatment: {model: fixed}"},
+		{"yaml", "treatment: {model: linear, full_hp: 50}"},
+		{"yaml", "treatment: {model: fixed, duration: fast}"},
+		{"json", `{"start_delay": 1000}`},
+		{"toml", "doctors = 10"},
+	} {
+		_, err := parseScenario([]byte(tc.data), tc.format)
+		assert.ErrorIs(err, ErrInvalidScenario, tc.data)
+	}
+
+	_, err := loadScenario("scenarios/not-exists.yaml")
+	assert.Error(err)
+}
+
+// 일정한 간격으로 도착하는 환자는 기다리지 않는다
+func TestSimulateUniformArrival(t *testing.T) {
+	assert := assert.New(t)
+
+	sc, err := parseScenario([]byte(`
+patients: 10
+doctors: 1
+start_delay: 0s
+arrival: {process: uniform, interval: 100ms}
+treatment: {model: fixed, duration: 10ms}
+`), "yaml")
+	assert.NoError(err)
+
+	result := simulate(sc, rand.New(rand.NewSource(1)))
+	assert.Zero(result.Dead)
+	assert.Equal(10, result.Cured)
+	assert.Equal(make([]time.Duration, 10), result.Waits)
+	assert.Equal(time.Millisecond*100, result.Busy)
+	assert.Equal(time.Millisecond*910, result.Elapsed)
+}
+
+// 포아송 도착: 치료 시간보다 도착 간격이 짧으면 대기가 생기고, 쉬는 의사가 다시 진료한다
+func TestSimulatePoissonArrival(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, discipline := range []string{DisciplineFIFO, DisciplineTriage, DisciplineEDF} {
+		sc := defaultScenario()
+		sc.Patients, sc.Doctors, sc.Discipline = 2000, 4, discipline
+		sc.Arrival = Arrival{Process: ArrivalPoisson, Rate: 50}
+		assert.NoError(sc.validate())
+
+		result := simulate(sc, rand.New(rand.NewSource(1)))
+		assert.Equal(sc.Patients, result.Dead+result.Cured, discipline)
+		assert.Len(result.Waits, result.Cured, discipline)
+
+		// 약 40초 동안 도착
+		assert.Greater(result.Elapsed, time.Second*30, discipline)
+		assert.Less(result.Elapsed, time.Second*50, discipline)
+	}
+}
+
+// 정규 분포 hp는 [min, max)로 제한된다
+func TestSeverityNormal(t *testing.T) {
+	assert := assert.New(t)
+
+	s := Severity{Distribution: SeverityNormal, Min: 10, Max: 20, Mean: 15, Stddev: 10}
+	r := rand.New(rand.NewSource(1))
+	seen := map[int]bool{}
+	for i := 0; i < 1000; i++ {
+		hp := s.hp(r)
+		assert.GreaterOrEqual(hp, 10)
+		assert.Less(hp, 20)
+		seen[hp] = true
+	}
+	assert.True(seen[10])
+	assert.True(seen[19])
+}
+
+// 명령행에서 지정한 값만 바꾸고, 0이나 음수는 기본값 대신 오류
+func TestScenarioOverride(t *testing.T) {
+	assert := assert.New(t)
+
+	base, err := loadScenario("scenarios/baseline.yaml")
+	assert.NoError(err)
+
+	set := func(names ...string) func(string) bool {
+		return func(name string) bool {
+			for _, n := range names {
+				if n == name {
+					return true
+				}
+			}
+			return false
+		}
+	}
+
+	sc, err := base.override(3, 7, 2, set())
+	assert.NoError(err)
+	assert.Equal(base, sc)
+
+	sc, err = base.override(3, 7, 2, set("trials", "doctors"))
+	assert.NoError(err)
+	assert.Equal(3, sc.Trials)
+	assert.Equal(base.Seed, sc.Seed)
+	assert.Equal(2, sc.Doctors)
+
+	for _, doctors := range []int{0, -1} {
+		_, err = base.override(3, 7, doctors, set("doctors"))
+		assert.ErrorIs(err, ErrInvalidScenario, doctors)
+	}
+	_, err = base.override(0, 7, 2, set("trials"))
+	assert.ErrorIs(err, ErrInvalidScenario)
+}
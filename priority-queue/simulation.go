@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"gostudy/pkg/pqueue"
+	"gostudy/pkg/sim"
+)
+
+// 시뮬레이션 시작 시각(가상 시간이므로 고정된 값을 사용)
+var simEpoch = time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)
+
+// 시뮬레이션 결과
+type SimResult struct {
+	Dead    int             // 사망자 수
+	Cured   int             // 치료한 환자 수
+	Treated []int           // 의사별 치료한 환자 수
+	Waits   []time.Duration // 치료한 환자가 도착해서 치료를 시작하기까지 기다린 시간(치료 순)
+	Busy    time.Duration   // 의사들이 치료한 시간의 합
+	Elapsed time.Duration   // 걸린 가상 시간
+	Events  uint64          // 처리한 이벤트 수
+}
+
+func (r SimResult) String() string {
+	return fmt.Sprintf("dead: %d, cured: %d, elapsed: %.2f(s), events: %d", r.Dead, r.Cured, r.Elapsed.Seconds(), r.Events)
+}
+
+// 랜덤한 환자 생성( hp: severity, age: 1-99 )
+// 같은 시드의 r이면 같은 환자들이 생성된다.
+func newPatient(r *rand.Rand, id int, visitAt time.Time, severity Severity) Patient {
+	age := 1 + r.Intn(99)
+	return Patient{id: id, age: age, hp: severity.hp(r), visitAt: visitAt}
+}
+
+// 환자 대기열(큐 규칙)
+type waitingRoom interface {
+	// 환자를 대기열에 추가
+	enter(p Patient)
+	// 다음 환자를 호출(없으면 false)
+	call() (Patient, bool)
+}
+
+// 도착 순서(Go 채널)
+type fifoRoom chan Patient
+
+func (q fifoRoom) enter(p Patient) {
+	q <- p
+}
+
+func (q fifoRoom) call() (Patient, bool) {
+	select {
+	case p := <-q:
+		return p, true
+	default:
+		return Patient{}, false
+	}
+}
+
+// 우선순위 큐
+type priorityRoom[P any] struct {
+	*pqueue.PriorityChannel[Patient, P]
+	priority func(p Patient) P
+}
+
+func (q priorityRoom[P]) enter(p Patient) {
+	if _, err := q.Push(p, q.priority(p)); err != nil {
+		fmt.Printf("enque: err=%v", err)
+	}
+}
+
+func (q priorityRoom[P]) call() (Patient, bool) {
+	p, err := q.Deque()
+	return p, err == nil
+}
+
+// 큐 규칙에 따른 대기열(EDF는 마감 시간이 지난 환자를 onDead로 알린다)
+func newWaitingRoom(discipline string, cap int, clk *sim.Engine, onDead func()) waitingRoom {
+	switch discipline {
+	case DisciplineFIFO:
+		return make(fifoRoom, cap)
+	case DisciplineTriage:
+		return priorityRoom[Triage]{pqueue.NewChannelFunc[Patient](cap, Triage.Less), Patient.Triage}
+	}
+
+	patients := pqueue.NewDeadlineChannel(cap, func(item pqueue.Item[Patient, time.Time]) {
+		onDead()
+	})
+	patients.SetClock(clk)
+	return priorityRoom[time.Time]{patients, Patient.Deadline}
+}
+
+// 시나리오를 이산 사건 시뮬레이션으로 한번 실행한다.
+//
+// 의사는 치료 시간만큼 잠드는 대신, 치료가 끝나는 시각에 다음 환자를 호출하는 이벤트를 예약한다.
+// 호출할 환자가 없으면 쉬다가, 환자가 도착하면 가장 오래 쉰 의사부터 다시 진료한다.
+// 대기열 규칙이 fifo, triage이면 호출한 환자가 죽었는지 확인하고, edf이면 대기열에서 사망 처리한다.
+func simulate(sc Scenario, r *rand.Rand) SimResult {
+	e := sim.New(simEpoch)
+	result := SimResult{Treated: make([]int, sc.Doctors)}
+
+	patients := newWaitingRoom(sc.Discipline, sc.Patients, e, func() { result.Dead++ })
+
+	// 쉬고 있는 의사(쉬기 시작한 순)
+	var idle []int
+
+	// 환자 진료: 다음 환자를 호출하고 치료가 끝나면 다시 호출
+	var cure func(doctor int)
+	cure = func(doctor int) {
+		for {
+			patient, ok := patients.call()
+			if !ok {
+				idle = append(idle, doctor)
+				return
+			}
+
+			// 죽었나?
+			if patient.IsDead(e.Now()) {
+				result.Dead++
+				continue
+			}
+
+			// 치료한다.
+			treattime := sc.Treatment.duration(r, patient.hp)
+			result.Cured++
+			result.Treated[doctor]++
+			result.Waits = append(result.Waits, e.Since(patient.visitAt))
+			result.Busy += treattime
+
+			// 치료 시간 후 다음 환자
+			e.Schedule(treattime, func() { cure(doctor) })
+			return
+		}
+	}
+
+	// 환자 발생!!! 다음 환자가 도착하는 이벤트를 예약한다(간격이 0이면 바로 도착)
+	arrived := 0
+	var arrive func()
+	arrive = func() {
+		for arrived < sc.Patients {
+			patients.enter(newPatient(r, arrived, e.Now(), sc.Severity))
+			arrived++
+
+			// 쉬고 있는 의사를 깨운다
+			if len(idle) > 0 {
+				doctor := idle[0]
+				idle = idle[1:]
+				cure(doctor)
+			}
+
+			if gap := sc.Arrival.next(r); gap > 0 && arrived < sc.Patients {
+				e.Schedule(gap, arrive)
+				return
+			}
+		}
+	}
+	arrive()
+
+	// 잠시 대기 후 진료 시작
+	for i := 0; i < sc.Doctors; i++ {
+		doctor := i
+		e.Schedule(time.Duration(*sc.StartDelay), func() { cure(doctor) })
+	}
+
+	e.Run()
+	result.Elapsed = e.Elapsed()
+	result.Events = e.Fired()
+	return result
+}
+
+// runWithPriorityQueue와 같은 모델을 이산 사건 시뮬레이션으로 실행한다.
+func simulateWithPriorityQueue(r *rand.Rand, patientCount, doctors int) SimResult {
+	sc := defaultScenario()
+	sc.Patients, sc.Doctors = patientCount, doctors
+	return simulate(sc, r)
+}
+
+// runWithGoChannel과 같은 모델을 이산 사건 시뮬레이션으로 실행한다.
+func simulateWithGoChannel(r *rand.Rand, patientCount, doctors int) SimResult {
+	sc := defaultScenario()
+	sc.Patients, sc.Doctors, sc.Discipline = patientCount, doctors, DisciplineFIFO
+	return simulate(sc, r)
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 10,000명의 환자도 실제 시간을 기다리지 않고 바로 시뮬레이션된다
+func TestSimulateWithPriorityQueue(t *testing.T) {
+	assert := assert.New(t)
+
+	result := simulateWithPriorityQueue(rand.New(rand.NewSource(1)), PATIENT_COUNT, 10)
+	t.Log(result)
+
+	// EDF: 의사 10명이면 모두 치료(실제 시간 실행 결과와 같다)
+	assert.Zero(result.Dead)
+	assert.Equal(PATIENT_COUNT, result.Cured)
+	assert.Len(result.Treated, 10)
+	total := 0
+	for _, n := range result.Treated {
+		total += n
+	}
+	assert.Equal(PATIENT_COUNT, total)
+
+	// 평균 치료 시간(약 55ms) * 1,000명 + 대기(1초)
+	assert.Greater(result.Elapsed, time.Second*50)
+	assert.Less(result.Elapsed, time.Second*65)
+}
+
+// FIFO(Go 채널)는 hp가 낮은 환자를 기다리게 하므로 사망자가 생긴다
+func TestSimulateWithGoChannel(t *testing.T) {
+	assert := assert.New(t)
+
+	result := simulateWithGoChannel(rand.New(rand.NewSource(1)), PATIENT_COUNT, 10)
+	t.Log(result)
+
+	assert.Positive(result.Dead)
+	assert.Equal(PATIENT_COUNT, result.Dead+result.Cured)
+
+	// 우선순위 큐보다 사망자가 많다
+	pq := simulateWithPriorityQueue(rand.New(rand.NewSource(1)), PATIENT_COUNT, 10)
+	assert.Greater(result.Dead, pq.Dead)
+}
+
+// 같은 시드면 같은 결과가 나온다
+func TestSimulationIsDeterministic(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, simulate := range []func(r *rand.Rand, patientCount, doctors int) SimResult{
+		simulateWithPriorityQueue,
+		simulateWithGoChannel,
+	} {
+		a := simulate(rand.New(rand.NewSource(7)), 2000, 3)
+		b := simulate(rand.New(rand.NewSource(7)), 2000, 3)
+		assert.Equal(a, b)
+		assert.Equal(2000, a.Dead+a.Cured)
+	}
+}
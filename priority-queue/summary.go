@@ -0,0 +1,192 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 시나리오 실행 결과 요약(시행 평균)
+type Summary struct {
+	Scenario    string    `json:"scenario" yaml:"scenario"`
+	Trials      int       `json:"trials" yaml:"trials"`
+	Seed        int64     `json:"seed" yaml:"seed"`
+	Patients    int       `json:"patients" yaml:"patients"`
+	Doctors     int       `json:"doctors" yaml:"doctors"`
+	Discipline  string    `json:"discipline" yaml:"discipline"`
+	Dead        Stat      `json:"dead" yaml:"dead"`
+	Cured       Stat      `json:"cured" yaml:"cured"`
+	Wait        WaitStat  `json:"wait" yaml:"wait"`                           // 치료를 시작하기까지 기다린 시간
+	Utilization Stat      `json:"utilization" yaml:"utilization"`             // 의사 가동률: 치료한 시간 / (의사 수 * 걸린 시간)
+	Elapsed     Duration  `json:"elapsed" yaml:"elapsed"`                     // 걸린 가상 시간
+	Took        Duration  `json:"took" yaml:"took"`                           // 실제로 걸린 시간(모든 시행)
+	Treated     []float64 `json:"treated,omitempty" yaml:"treated,omitempty"` // 의사별 치료한 환자 수
+}
+
+// 시행별 값의 평균, 최소, 최대
+type Stat struct {
+	Mean float64 `json:"mean" yaml:"mean"`
+	Min  float64 `json:"min" yaml:"min"`
+	Max  float64 `json:"max" yaml:"max"`
+}
+
+// 대기 시간 분포(시행별 값의 평균)
+type WaitStat struct {
+	Mean Duration `json:"mean" yaml:"mean"`
+	P50  Duration `json:"p50" yaml:"p50"`
+	P90  Duration `json:"p90" yaml:"p90"`
+	P99  Duration `json:"p99" yaml:"p99"`
+	Max  Duration `json:"max" yaml:"max"`
+}
+
+// 시행 하나의 요약
+type trialSum struct {
+	Dead        int
+	Cured       int
+	Wait        WaitStat
+	Utilization float64
+	Elapsed     time.Duration
+}
+
+// 시행 결과 요약
+func summarizeTrial(result SimResult, doctors int) trialSum {
+	sum := trialSum{Dead: result.Dead, Cured: result.Cured, Elapsed: result.Elapsed}
+	if doctors > 0 && result.Elapsed > 0 {
+		sum.Utilization = float64(result.Busy) / (float64(doctors) * float64(result.Elapsed))
+	}
+
+	if n := len(result.Waits); n > 0 {
+		waits := append([]time.Duration(nil), result.Waits...)
+		sort.Slice(waits, func(i, j int) bool { return waits[i] < waits[j] })
+
+		var total time.Duration
+		for _, w := range waits {
+			total += w
+		}
+		percentile := func(p float64) Duration {
+			return Duration(waits[int(p*float64(n-1))])
+		}
+		sum.Wait = WaitStat{
+			Mean: Duration(total / time.Duration(n)),
+			P50:  percentile(0.50),
+			P90:  percentile(0.90),
+			P99:  percentile(0.99),
+			Max:  Duration(waits[n-1]),
+		}
+	}
+	return sum
+}
+
+// 시나리오를 sc.Trials 번 실행하고 요약한다(i번째 시행의 시드: sc.Seed + i)
+func runScenario(sc Scenario) Summary {
+	begin := time.Now()
+
+	summary := Summary{
+		Scenario:   sc.Name,
+		Trials:     sc.Trials,
+		Seed:       sc.Seed,
+		Patients:   sc.Patients,
+		Doctors:    sc.Doctors,
+		Discipline: sc.Discipline,
+	}
+
+	var trials []trialSum
+	treated := make([]int, sc.Doctors)
+	for i := 0; i < sc.Trials; i++ {
+		result := simulate(sc, rand.New(rand.NewSource(sc.Seed+int64(i))))
+		trials = append(trials, summarizeTrial(result, sc.Doctors))
+		for doctor, n := range result.Treated {
+			treated[doctor] += n
+		}
+	}
+
+	var dead, cured, utilization []float64
+	var wait WaitStat
+	var elapsed time.Duration
+	for _, t := range trials {
+		dead = append(dead, float64(t.Dead))
+		cured = append(cured, float64(t.Cured))
+		utilization = append(utilization, t.Utilization)
+		wait.Mean += t.Wait.Mean
+		wait.P50 += t.Wait.P50
+		wait.P90 += t.Wait.P90
+		wait.P99 += t.Wait.P99
+		wait.Max += t.Wait.Max
+		elapsed += t.Elapsed
+	}
+	summary.Dead = newStat(dead)
+	summary.Cured = newStat(cured)
+	summary.Utilization = newStat(utilization)
+	if n := Duration(len(trials)); n > 0 {
+		summary.Wait = WaitStat{
+			Mean: (wait.Mean / n).round(),
+			P50:  (wait.P50 / n).round(),
+			P90:  (wait.P90 / n).round(),
+			P99:  (wait.P99 / n).round(),
+			Max:  (wait.Max / n).round(),
+		}
+		summary.Elapsed = (Duration(elapsed) / n).round()
+		for _, total := range treated {
+			summary.Treated = append(summary.Treated, roundStat(float64(total)/float64(n)))
+		}
+	}
+	summary.Took = Duration(time.Since(begin)).round()
+	return summary
+}
+
+// 평균, 최소, 최대(소수점 4자리)
+func newStat(values []float64) Stat {
+	if len(values) == 0 {
+		return Stat{}
+	}
+	s := Stat{Min: values[0], Max: values[0]}
+	for _, v := range values {
+		s.Mean += v
+		s.Min = math.Min(s.Min, v)
+		s.Max = math.Max(s.Max, v)
+	}
+	s.Mean /= float64(len(values))
+	return Stat{Mean: roundStat(s.Mean), Min: roundStat(s.Min), Max: roundStat(s.Max)}
+}
+
+// 소수점 4자리로 반올림
+func roundStat(v float64) float64 {
+	return math.Round(v*1e4) / 1e4
+}
+
+// 요약에 표시하기 좋게 마이크로초 단위로 반올림
+func (d Duration) round() Duration {
+	return Duration(time.Duration(d).Round(time.Microsecond))
+}
+
+// 요약을 출력한다(format: json, yaml)
+// 여러 요약은 json이면 객체를 차례로, yaml이면 문서(---)로 나누어 출력한다.
+func printSummaries(w io.Writer, summaries []Summary, format string) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		for _, summary := range summaries {
+			if err := enc.Encode(summary); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "yaml", "yml":
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		for _, summary := range summaries {
+			if err := enc.Encode(summary); err != nil {
+				return err
+			}
+		}
+		return enc.Close()
+	}
+	return fmt.Errorf("unknown summary format: %q", format)
+}
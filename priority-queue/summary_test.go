@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 대기 시간 분포와 가동률
+func TestSummarizeTrial(t *testing.T) {
+	assert := assert.New(t)
+
+	result := SimResult{Dead: 1, Cured: 100, Busy: time.Second * 3, Elapsed: time.Second * 2}
+	for i := 100; i > 0; i-- {
+		result.Waits = append(result.Waits, time.Millisecond*time.Duration(i))
+	}
+
+	sum := summarizeTrial(result, 2)
+	assert.Equal(1, sum.Dead)
+	assert.Equal(100, sum.Cured)
+	assert.Equal(0.75, sum.Utilization)
+	assert.Equal(WaitStat{
+		Mean: Duration(time.Microsecond * 50500),
+		P50:  Duration(time.Millisecond * 50),
+		P90:  Duration(time.Millisecond * 90),
+		P99:  Duration(time.Millisecond * 99),
+		Max:  Duration(time.Millisecond * 100),
+	}, sum.Wait)
+
+	// 원래 순서는 바뀌지 않는다
+	assert.Equal(time.Millisecond*100, result.Waits[0])
+
+	// 치료한 환자가 없으면 0
+	assert.Equal(trialSum{Dead: 3}, summarizeTrial(SimResult{Dead: 3}, 0))
+}
+
+// 시행별 결과의 평균, 최소, 최대
+func TestRunScenario(t *testing.T) {
+	assert := assert.New(t)
+
+	sc := defaultScenario()
+	sc.Name, sc.Trials, sc.Patients, sc.Doctors, sc.Discipline = "fifo", 5, 2000, 3, DisciplineFIFO
+	summary := runScenario(sc)
+
+	assert.Equal("fifo", summary.Scenario)
+	assert.Equal(5, summary.Trials)
+	assert.Equal(float64(sc.Patients), summary.Dead.Mean+summary.Cured.Mean)
+	assert.LessOrEqual(summary.Dead.Min, summary.Dead.Mean)
+	assert.LessOrEqual(summary.Dead.Mean, summary.Dead.Max)
+	assert.Less(summary.Dead.Min, summary.Dead.Max)
+	assert.Greater(summary.Utilization.Min, 0.9)
+	assert.LessOrEqual(summary.Utilization.Max, 1.0)
+	assert.Greater(summary.Wait.P90, summary.Wait.P50)
+	assert.Positive(summary.Elapsed)
+	assert.Len(summary.Treated, 3)
+
+	// 같은 시드면 같은 결과
+	again := runScenario(sc)
+	summary.Took, again.Took = 0, 0
+	assert.Equal(summary, again)
+}
+
+// json은 객체를 차례로, yaml은 문서를 나누어 출력한다
+func TestPrintSummaries(t *testing.T) {
+	assert := assert.New(t)
+
+	summaries := []Summary{
+		{Scenario: "a", Dead: Stat{Mean: 1.5}, Wait: WaitStat{P50: Duration(time.Millisecond * 1500)}},
+		{Scenario: "b", Treated: []float64{1, 2}},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(printSummaries(&buf, summaries, "json"))
+	dec := json.NewDecoder(&buf)
+	for _, expected := range summaries {
+		var m map[string]interface{}
+		assert.NoError(dec.Decode(&m))
+		assert.Equal(expected.Scenario, m["scenario"])
+	}
+
+	buf.Reset()
+	assert.NoError(printSummaries(&buf, summaries, "json"))
+	assert.Contains(buf.String(), `"p50": "1.5s"`)
+	assert.Contains(buf.String(), `"mean": 1.5`)
+
+	buf.Reset()
+	assert.NoError(printSummaries(&buf, summaries, "yaml"))
+	docs := strings.Split(buf.String(), "---\n")
+	assert.Len(docs, 2)
+	assert.Contains(docs[0], "scenario: a\n")
+	assert.Contains(docs[0], "p50: 1.5s\n")
+	assert.NotContains(docs[0], "treated:")
+	assert.Contains(docs[1], "treated:\n  - 1\n  - 2\n")
+
+	assert.Error(printSummaries(&buf, summaries, "xml"))
+}